@@ -0,0 +1,169 @@
+package ginx
+
+import (
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"github.com/ginx-contribs/ginx/constant/headers"
+	"github.com/ginx-contribs/ginx/constant/mimes"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"time"
+)
+
+// AdminOptions configures the admin server, which serves debug endpoints on a separate address:
+//
+//	GET  /livez         liveness, it is ok as long as admin server is serving, or the liveness report of health registry
+//	GET  /readyz        readiness, it is ok only if server state is running, or the readiness report of health registry
+//	GET  /routes        route table with effective metadata
+//	GET  /options       effective server options
+//	GET  /hooks         execution status of hooks
+//	POST /reload        reload config, see Server.Reload
+//	GET  /metrics       metrics in prometheus text format, only if metrics registry is set, see WithMetrics
+//	GET  /debug/vars    expvar
+//	GET  /debug/pprof/  pprof
+type AdminOptions struct {
+	// tcp address in the form "host:port"
+	Address string `mapstructure:"address"`
+}
+
+// AdminAddr returns the address admin server is listening on, it returns nil if admin server is not running.
+func (s *Server) AdminAddr() net.Addr {
+	if s.adminListener == nil {
+		return nil
+	}
+	return s.adminListener.Addr()
+}
+
+// adminListenerName is the name of admin listener passed to new process by graceful restart
+const adminListenerName = "admin"
+
+func (s *Server) startAdmin() error {
+	options := s.options.Admin
+	if options == nil || options.Address == "" || s.admin != nil {
+		return nil
+	}
+
+	// take the listener passed by parent process first, like the main listeners
+	if err := s.loadInherited(); err != nil {
+		return err
+	}
+	var l net.Listener
+	if inherited, ok := takeInheritedByName(&s.inherited, adminListenerName); ok {
+		l = inherited.Listener
+	} else {
+		var err error
+		if l, err = net.Listen("tcp", options.Address); err != nil {
+			return fmt.Errorf("admin: %w", err)
+		}
+	}
+	s.adminListener = l
+	s.admin = &http.Server{Handler: s.adminHandler(), ReadHeaderTimeout: time.Second * 10}
+
+	slog.InfoContext(s.ctx, fmt.Sprintf("admin server is listening at %s://%s", l.Addr().Network(), l.Addr()))
+	go func() {
+		if err := s.admin.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.ErrorContext(s.ctx, "admin server failed", slog.Any("error", err))
+		}
+	}()
+	return nil
+}
+
+func (s *Server) stopAdmin() {
+	if s.admin == nil {
+		return
+	}
+	_ = s.admin.Close()
+	s.admin = nil
+	s.adminListener = nil
+}
+
+type adminRoute struct {
+	Method  string   `json:"method"`
+	Path    string   `json:"path"`
+	Group   string   `json:"group"`
+	Handler string   `json:"handler"`
+	Meta    MetaData `json:"meta"`
+}
+
+func (s *Server) adminHandler() http.Handler {
+	mux := http.NewServeMux()
+
+	if s.health != nil {
+		mux.Handle("GET /livez", s.health.LivenessHandler())
+		mux.Handle("GET /readyz", s.health.ReadinessHandler())
+	} else {
+		mux.HandleFunc("GET /livez", func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusOK, map[string]any{"status": "ok"})
+		})
+
+		mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+			state := s.State()
+			code := http.StatusOK
+			if state != StateRunning {
+				code = http.StatusServiceUnavailable
+			}
+			writeJSON(w, code, map[string]any{"status": state})
+		})
+	}
+
+	mux.HandleFunc("GET /routes", func(w http.ResponseWriter, r *http.Request) {
+		routes := make([]adminRoute, 0)
+		s.Walk(func(info RouteInfo) {
+			if info.IsGroup {
+				return
+			}
+			route := adminRoute{Method: info.Method, Path: info.FullPath, Meta: info.Meta}
+			if info.Group != nil {
+				route.Group = info.Group.FullPath
+			}
+			if info.Handler != nil {
+				route.Handler = funcName(info.Handler)
+			}
+			routes = append(routes, route)
+		})
+		writeJSON(w, http.StatusOK, routes)
+	})
+
+	mux.HandleFunc("GET /options", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, s.options)
+	})
+
+	mux.HandleFunc("GET /hooks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, s.HookStatus())
+	})
+
+	mux.HandleFunc("POST /reload", func(w http.ResponseWriter, r *http.Request) {
+		restartRequired, err := s.Reload()
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"restartRequired": restartRequired})
+	})
+
+	if s.metrics != nil {
+		mux.Handle("GET /metrics", s.metrics.registry.Handler())
+	}
+
+	mux.Handle("GET /debug/vars", expvar.Handler())
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, code int, data any) {
+	w.Header().Set(headers.ContentType, mimes.ApplicationJSON)
+	w.WriteHeader(code)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(data); err != nil {
+		slog.Error("admin: failed to encode response", slog.Any("error", err))
+	}
+}
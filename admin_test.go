@@ -0,0 +1,123 @@
+package ginx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/ginx-contribs/ginx/pkg/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestAdmin(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var server *Server
+	get := func(path string, v any) int {
+		res, err := http.Get(fmt.Sprintf("http://%s%s", server.AdminAddr(), path))
+		if err != nil {
+			return 0
+		}
+		defer res.Body.Close()
+		if v != nil {
+			_ = json.NewDecoder(res.Body).Decode(v)
+		}
+		return res.StatusCode
+	}
+
+	var (
+		starting, running, stopping map[string]string
+		live                        int
+		routes                      []map[string]any
+		hooks                       []HookStatus
+		pprof, metricsCode          int
+	)
+	registry := metrics.NewRegistry()
+	server = New(
+		WithCtx(ctx),
+		WithAddress("127.0.0.1:0"),
+		WithAdmin("127.0.0.1:0"),
+		WithMetrics(registry),
+		WithBeforeStarting(func(ctx context.Context) error {
+			get("/readyz", &starting)
+			return nil
+		}),
+		WithAfterStarted(func(ctx context.Context) error {
+			get("/routes", &routes)
+			pprof = get("/debug/pprof/", nil)
+			metricsCode = get("/metrics", nil)
+			go func() {
+				defer cancel()
+				for i := 0; i < 100 && running["status"] != "running"; i++ {
+					running = nil
+					get("/readyz", &running)
+					time.Sleep(time.Millisecond * 10)
+				}
+			}()
+			return nil
+		}),
+		WithOnShutdown(func(ctx context.Context) error {
+			get("/readyz", &stopping)
+			live = get("/livez", nil)
+			get("/hooks", &hooks)
+			return nil
+		}),
+	)
+	server.RouterGroup().MGET("/ping", M{{Key: "role", Val: "admin"}}, func(ctx *gin.Context) {})
+
+	require.NoError(t, server.Spin())
+	assert.Equal(t, "starting", starting["status"])
+	assert.Equal(t, "running", running["status"])
+	assert.Equal(t, "stopping", stopping["status"])
+	assert.Equal(t, http.StatusOK, live)
+	assert.Equal(t, http.StatusOK, pprof)
+	assert.Equal(t, http.StatusOK, metricsCode)
+	assert.Nil(t, server.AdminAddr())
+	assert.Equal(t, StateStopped, server.State())
+
+	require.Len(t, routes, 1)
+	assert.Equal(t, "/ping", routes[0]["path"])
+	assert.Equal(t, map[string]any{"role": "admin"}, routes[0]["meta"])
+
+	require.Len(t, hooks, 3)
+	assert.Equal(t, PhaseBeforeStarting, hooks[0].Phase)
+	assert.Equal(t, "done", hooks[1].Status)
+	assert.Equal(t, "running", hooks[2].Status)
+
+	var buf bytes.Buffer
+	_, _ = registry.WriteTo(&buf)
+	assert.Contains(t, buf.String(), `ginx_hook_duration_seconds_count{phase="afterStarted"`)
+}
+
+func TestConnMetrics(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := newServerMetrics(registry)
+	conn, _ := net.Pipe()
+	var states []http.ConnState
+	connState := m.connState(func(conn net.Conn, state http.ConnState) {
+		states = append(states, state)
+	})
+
+	connState(conn, http.StateNew)
+	connState(conn, http.StateActive)
+	connState(conn, http.StateIdle)
+
+	var buf bytes.Buffer
+	_, _ = registry.WriteTo(&buf)
+	assert.Contains(t, buf.String(), "ginx_http_connections_total 1\n")
+	assert.Contains(t, buf.String(), `ginx_http_open_connections{state="idle"} 1`)
+	assert.Contains(t, buf.String(), `ginx_http_open_connections{state="active"} 0`)
+
+	connState(conn, http.StateClosed)
+	buf.Reset()
+	_, _ = registry.WriteTo(&buf)
+	assert.Contains(t, buf.String(), `ginx_http_open_connections{state="idle"} 0`)
+	assert.Len(t, states, 4)
+}
@@ -0,0 +1,80 @@
+package ginx
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/ginx-contribs/ginx/pkg/config"
+)
+
+// LoadOptions loads server options from config files and environment variables, the result could be applied by WithOptions.
+//
+//	options, err := ginx.LoadOptions(config.WithFiles("server.yaml"), config.WithEnv("GINX"))
+func LoadOptions(opts ...config.Option) (Options, error) {
+	var options Options
+	if err := config.Load(&options, opts...); err != nil {
+		return Options{}, err
+	}
+	return options, nil
+}
+
+// Validate checks whether options are valid, it is called by LoadOptions
+func (o *Options) Validate() error {
+	var errs []error
+
+	switch o.Mode {
+	case "", gin.DebugMode, gin.ReleaseMode, gin.TestMode:
+	default:
+		errs = append(errs, fmt.Errorf("unknown mode %q", o.Mode))
+	}
+
+	for _, l := range o.Listeners {
+		switch l.Network {
+		case "", "tcp", "tcp4", "tcp6", "unix":
+		default:
+			errs = append(errs, fmt.Errorf("listener %s: unknown network", l))
+		}
+		if l.Address == "" {
+			errs = append(errs, fmt.Errorf("listener %s: empty address", l))
+		}
+	}
+
+	if o.TLS != nil {
+		if err := o.TLS.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("tls: %w", err))
+		}
+	}
+
+	if o.HTTP2 != nil && o.HTTP2.MaxReadFrameSize != 0 && (o.HTTP2.MaxReadFrameSize < 1<<14 || o.HTTP2.MaxReadFrameSize > 1<<24-1) {
+		errs = append(errs, fmt.Errorf("http2: max read frame size %d out of range", o.HTTP2.MaxReadFrameSize))
+	}
+	return errors.Join(errs...)
+}
+
+func (o *TLSOptions) validate() error {
+	if (o.Cert == "") != (o.Key == "") {
+		return errors.New("both cert and key are required")
+	}
+	if o.Cert == "" && len(o.Certificates) == 0 {
+		return errors.New("no certificate configured")
+	}
+	for _, pair := range o.Certificates {
+		if pair.Cert == "" || pair.Key == "" {
+			return errors.New("both cert and key are required")
+		}
+	}
+	if _, err := parseTLSVersion(o.MinVersion); err != nil {
+		return err
+	}
+	if _, err := parseTLSVersion(o.MaxVersion); err != nil {
+		return err
+	}
+	if _, err := parseCipherSuites(o.CipherSuites); err != nil {
+		return err
+	}
+	if _, err := parseCurves(o.CurvePreferences); err != nil {
+		return err
+	}
+	_, err := parseClientAuth(o.ClientAuth, len(o.ClientCAs) > 0)
+	return err
+}
@@ -0,0 +1,42 @@
+package ginx
+
+import (
+	"github.com/ginx-contribs/ginx/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadOptions(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "server.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(`
+server:
+  mode: debug
+  address: ":8080"
+  readTimeout: 30s
+  maxMultipartMemory: 32MB
+  listeners:
+    - network: unix
+      address: /tmp/ginx.sock
+      fileMode: "0660"
+  http2:
+    h2c: true
+`), 0o600))
+	t.Setenv("GINX_SERVER_MAX_HEADER_BYTES", "64KB")
+
+	options, err := LoadOptions(config.WithFiles(file), config.WithEnv("GINX"), config.WithKey("server"))
+	require.NoError(t, err)
+	assert.Equal(t, "debug", options.Mode)
+	assert.Equal(t, 30*time.Second, options.ReadTimeout)
+	assert.Equal(t, int64(32<<20), options.MaxMultipartMemory)
+	assert.Equal(t, 64<<10, options.MaxHeaderBytes)
+	assert.Equal(t, []ListenerOptions{{Network: "unix", Address: "/tmp/ginx.sock", FileMode: 0o660}}, options.Listeners)
+	assert.True(t, options.HTTP2.H2C)
+
+	t.Setenv("GINX_SERVER_TLS_MIN_VERSION", "1.4")
+	_, err = LoadOptions(config.WithFiles(file), config.WithEnv("GINX"), config.WithKey("server"))
+	assert.ErrorContains(t, err, "tls")
+}
@@ -41,6 +41,7 @@ const (
 
 	// Response context
 	Allow       = "Allow"
+	Link        = "Link"
 	Server      = "Server"
 	ServerLower = "server"
 
@@ -63,6 +64,7 @@ const (
 	AcceptCharset  = "Accept-Charset"
 	AcceptEncoding = "Accept-Encoding"
 	AcceptLanguage = "Accept-Language"
+	Vary           = "Vary"
 	AltSvc         = "Alt-Svc"
 
 	// Cors
@@ -76,6 +78,10 @@ const (
 	AccessControlAllowCredentials    = "Access-Control-Allow-Credentials"
 	AccessControlAllowPrivateNetwork = "Access-Control-Allow-Private-Network"
 
+	// W3C trace context
+	Traceparent = "Traceparent"
+	Tracestate  = "Tracestate"
+
 	// User custom
 	XRequestId = "X-Request-ID"
 )
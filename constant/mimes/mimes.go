@@ -11,6 +11,7 @@ const (
 	TextHtml              = "text/html"
 	TextCss               = "text/css"
 	TextJavascript        = "text/javascript"
+	TextXML               = "text/xml"
 	MultipartPOSTForm     = "multipart/form-data"
 
 	// MIME application
@@ -38,6 +39,13 @@ const (
 	ApplicationOpenXMLExcel = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
 	ApplicationOpenXMLPPT   = "application/vnd.openxmlformats-officedocument.presentationml.presentation"
 	PROTOBUF                = "application/x-protobuf"
+	ApplicationYAML         = "application/yaml"
+	ApplicationXYAML        = "application/x-yaml"
+	ApplicationTOML         = "application/toml"
+	ApplicationMsgPack      = "application/msgpack"
+	ApplicationCBOR         = "application/cbor"
+	ApplicationProblemJSON  = "application/problem+json"
+	ApplicationProblemXML   = "application/problem+xml"
 
 	// MIME image
 	ImageJPEG         = "image/jpeg"
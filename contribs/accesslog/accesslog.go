@@ -5,8 +5,11 @@ import (
 	"github.com/dstgo/size"
 	"github.com/gin-gonic/gin"
 	"github.com/ginx-contribs/ginx/constant/headers"
+	"github.com/ginx-contribs/ginx/pkg/trace"
 	"log/slog"
 	"net/http/httputil"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -21,6 +24,7 @@ func DefaultOptions(logger *slog.Logger, msg string) Options {
 		ShowPath:         true,
 		ShowRoute:        true,
 		ShowRequestId:    true,
+		ShowTraceId:      true,
 		ShowRequestSize:  true,
 		ShowResponseSize: true,
 		ShowError:        true,
@@ -28,18 +32,20 @@ func DefaultOptions(logger *slog.Logger, msg string) Options {
 }
 
 type Options struct {
-	Logger           *slog.Logger
-	Msg              string
-	ShowCost         bool
-	ShowIp           bool
-	ShowAgent        bool
-	ShowURL          bool
-	ShowPath         bool
-	ShowRoute        bool
-	ShowRequestId    bool
-	ShowRequestSize  bool
-	ShowResponseSize bool
-	ShowError        bool
+	Logger        *slog.Logger `mapstructure:"-"`
+	Msg           string       `mapstructure:"msg"`
+	ShowCost      bool         `mapstructure:"showCost"`
+	ShowIp        bool         `mapstructure:"showIp"`
+	ShowAgent     bool         `mapstructure:"showAgent"`
+	ShowURL       bool         `mapstructure:"showURL"`
+	ShowPath      bool         `mapstructure:"showPath"`
+	ShowRoute     bool         `mapstructure:"showRoute"`
+	ShowRequestId bool         `mapstructure:"showRequestId"`
+	// trace id and span id of the span in request context, see contribs/tracing
+	ShowTraceId      bool `mapstructure:"showTraceId"`
+	ShowRequestSize  bool `mapstructure:"showRequestSize"`
+	ShowResponseSize bool `mapstructure:"showResponseSize"`
+	ShowError        bool `mapstructure:"showError"`
 }
 
 // AccessLog records server access logs
@@ -101,6 +107,12 @@ func AccessLog(options Options) gin.HandlerFunc {
 			}
 		}
 
+		if options.ShowTraceId {
+			if sc, ok := trace.SpanContextFromContext(ctx.Request.Context()); ok {
+				attrs = append(attrs, slog.String("trace-id", sc.TraceID.String()), slog.String("span-id", sc.SpanID.String()))
+			}
+		}
+
 		if options.ShowError && len(ctx.Errors) != 0 {
 			attrs = append(attrs, slog.String("errors", ctx.Errors.String()))
 		}
@@ -109,6 +121,37 @@ func AccessLog(options Options) gin.HandlerFunc {
 	}
 }
 
+// Handler is an access log middleware whose options could be updated at runtime
+type Handler struct {
+	mu      sync.Mutex
+	options Options
+	handler atomic.Pointer[gin.HandlerFunc]
+}
+
+// NewHandler creates an access log handler with the provided options, use Handle as gin middleware.
+func NewHandler(options Options) *Handler {
+	h := new(Handler)
+	h.Update(options)
+	return h
+}
+
+// Handle is the gin middleware
+func (h *Handler) Handle(ctx *gin.Context) {
+	(*h.handler.Load())(ctx)
+}
+
+// Update replaces the options, the logger is kept if the new one is nil, it is safe to call while serving.
+func (h *Handler) Update(options Options) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if options.Logger == nil {
+		options.Logger = h.options.Logger
+	}
+	h.options = options
+	handler := AccessLog(options)
+	h.handler.Store(&handler)
+}
+
 func roundSize(s int) size.Size {
 	if s < 0 {
 		return size.NewInt(0, size.B)
@@ -0,0 +1,48 @@
+package authz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ginx-contribs/ginx"
+	"log/slog"
+)
+
+// Audit walks the route table of server, returns the routes which have no authorization metadata,
+// routes declared as Public are considered declared.
+func Audit(server *ginx.Server) []ginx.RouteInfo {
+	var undeclared []ginx.RouteInfo
+	server.Walk(func(info ginx.RouteInfo) {
+		if info.IsGroup || info.Meta.Has(Public.Name()) || declared(info.Meta) {
+			return
+		}
+		undeclared = append(undeclared, info)
+	})
+	return undeclared
+}
+
+// AuditHook returns a hook which logs the routes without authorization metadata as warnings,
+// and returns an error if any route declares an invalid policy expression. It is designed to use with ginx.WithBeforeStarting.
+func AuditHook(server *ginx.Server, logger *slog.Logger) ginx.HookFn {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return func(ctx context.Context) error {
+		for _, info := range Audit(server) {
+			logger.WarnContext(ctx, "route has no authorization metadata", slog.String("method", info.Method), slog.String("path", info.FullPath))
+		}
+
+		var errs []error
+		server.Walk(func(info ginx.RouteInfo) {
+			if info.IsGroup {
+				return
+			}
+			if expr, ok := Policy.Get(info.Meta); ok && expr != "" {
+				if _, err := compilePolicy(expr); err != nil {
+					errs = append(errs, fmt.Errorf("route %s %s: %w", info.Method, info.FullPath, err))
+				}
+			}
+		})
+		return errors.Join(errs...)
+	}
+}
@@ -0,0 +1,218 @@
+package authz
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/ginx-contribs/ginx"
+	"github.com/ginx-contribs/ginx/pkg/resp"
+	"github.com/ginx-contribs/ginx/pkg/resp/statuserr"
+	"slices"
+)
+
+var (
+	ErrUnauthenticated = errors.New("unauthenticated")
+	ErrForbidden       = errors.New("permission denied")
+)
+
+// authorization metadata keys
+var (
+	// Public declares the route can be accessed by anyone, authorization will be skipped.
+	Public = ginx.NewKey[bool]("public")
+	// Role declares the minimum role required to access the route, roles inherit it by Hierarchy are also allowed.
+	Role = ginx.NewKey[string]("role")
+	// Roles declares the roles allowed to access the route, principal should have any of them.
+	Roles = ginx.NewKey[[]string]("roles")
+	// Scopes declares the scopes required to access the route, principal should have all of them.
+	Scopes = ginx.NewKey[[]string]("scopes", ginx.WithMerge(ginx.MergeUnion))
+	// Permissions declares the permissions required to access the route, principal should have all of them.
+	Permissions = ginx.NewKey[[]string]("permissions", ginx.WithMerge(ginx.MergeUnion))
+	// Policy declares a policy expression, see ParsePolicy for syntax.
+	Policy = ginx.NewKey[string]("policy")
+)
+
+type Options struct {
+	Extractor PrincipalExtractor
+	// role inheritance
+	Hierarchy Hierarchy
+	// permissions granted to roles, key is role
+	RolePermissions map[string][]string
+	// deny the routes without any authorization metadata
+	DenyUndeclared bool
+	ErrorHandler   func(ctx *gin.Context, err error)
+}
+
+type Option func(options *Options)
+
+func WithExtractor(extractor PrincipalExtractor) Option {
+	return func(options *Options) {
+		options.Extractor = extractor
+	}
+}
+
+func WithHierarchy(hierarchy Hierarchy) Option {
+	return func(options *Options) {
+		options.Hierarchy = hierarchy
+	}
+}
+
+func WithRolePermissions(permissions map[string][]string) Option {
+	return func(options *Options) {
+		options.RolePermissions = permissions
+	}
+}
+
+func WithDenyUndeclared(deny bool) Option {
+	return func(options *Options) {
+		options.DenyUndeclared = deny
+	}
+}
+
+func WithErrorHandler(handler func(ctx *gin.Context, err error)) Option {
+	return func(options *Options) {
+		options.ErrorHandler = handler
+	}
+}
+
+// Authorize returns a handler which checks the principal against the authorization metadata of route,
+// it responds 401 if principal can not be extracted, and responds 403 if principal is not allowed.
+func Authorize(opts ...Option) gin.HandlerFunc {
+	var options Options
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if options.Extractor == nil {
+		options.Extractor = ExtractorFunc(func(ctx *gin.Context) (*Principal, error) {
+			if principal, ok := FromCtx(ctx); ok {
+				return principal, nil
+			}
+			return nil, ErrUnauthenticated
+		})
+	}
+
+	if options.ErrorHandler == nil {
+		options.ErrorHandler = func(ctx *gin.Context, err error) {
+			if errors.Is(err, ErrUnauthenticated) {
+				resp.Fail(ctx).Error(statuserr.UnAuthorized(err)).JSON()
+			} else {
+				resp.Fail(ctx).Error(statuserr.Forbidden(err)).JSON()
+			}
+			ctx.Abort()
+		}
+	}
+
+	authorizer := &authorizer{options: options}
+
+	return func(ctx *gin.Context) {
+		meta := ginx.MetaFromCtx(ctx)
+		if public, _ := Public.Get(meta); public {
+			ctx.Next()
+			return
+		}
+
+		if !declared(meta) {
+			if options.DenyUndeclared {
+				options.ErrorHandler(ctx, ErrForbidden)
+			} else {
+				ctx.Next()
+			}
+			return
+		}
+
+		principal, err := options.Extractor.Extract(ctx)
+		if err != nil || principal == nil {
+			if err == nil {
+				err = ErrUnauthenticated
+			} else if !errors.Is(err, ErrUnauthenticated) {
+				err = fmt.Errorf("%w: %w", ErrUnauthenticated, err)
+			}
+			options.ErrorHandler(ctx, err)
+			return
+		}
+		setPrincipal(ctx, principal)
+
+		if err := authorizer.check(meta, principal); err != nil {
+			options.ErrorHandler(ctx, err)
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// declared reports whether metadata has any authorization declaration
+func declared(meta ginx.MetaData) bool {
+	for _, key := range []string{Role.Name(), Roles.Name(), Scopes.Name(), Permissions.Name(), Policy.Name()} {
+		if meta.Has(key) {
+			return true
+		}
+	}
+	return false
+}
+
+type authorizer struct {
+	options Options
+}
+
+// check returns nil if principal satisfies all the declarations in metadata
+func (a *authorizer) check(meta ginx.MetaData, principal *Principal) error {
+	roles := a.options.Hierarchy.expand(principal.Roles)
+
+	if role, ok := Role.Get(meta); ok && role != "" {
+		if _, has := roles[role]; !has {
+			return fmt.Errorf("%w: role %s is required", ErrForbidden, role)
+		}
+	}
+
+	if allowed, ok := Roles.Get(meta); ok && len(allowed) > 0 {
+		if !slices.ContainsFunc(allowed, func(role string) bool {
+			_, has := roles[role]
+			return has
+		}) {
+			return fmt.Errorf("%w: any of roles %v is required", ErrForbidden, allowed)
+		}
+	}
+
+	if scopes, ok := Scopes.Get(meta); ok {
+		for _, scope := range scopes {
+			if !slices.Contains(principal.Scopes, scope) {
+				return fmt.Errorf("%w: scope %s is required", ErrForbidden, scope)
+			}
+		}
+	}
+
+	permissions := a.permissions(principal, roles)
+	if required, ok := Permissions.Get(meta); ok {
+		for _, permission := range required {
+			if _, has := permissions[permission]; !has {
+				return fmt.Errorf("%w: permission %s is required", ErrForbidden, permission)
+			}
+		}
+	}
+
+	if expr, ok := Policy.Get(meta); ok && expr != "" {
+		policy, err := compilePolicy(expr)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrForbidden, err)
+		}
+		if !policy.Eval(evalContext{principal: principal, roles: roles, permissions: permissions}) {
+			return fmt.Errorf("%w: policy %q is not satisfied", ErrForbidden, expr)
+		}
+	}
+
+	return nil
+}
+
+// permissions returns permissions owned by principal itself and granted by its roles
+func (a *authorizer) permissions(principal *Principal, roles map[string]struct{}) map[string]struct{} {
+	permissions := make(map[string]struct{}, len(principal.Permissions))
+	for _, permission := range principal.Permissions {
+		permissions[permission] = struct{}{}
+	}
+	for role := range roles {
+		for _, permission := range a.options.RolePermissions[role] {
+			permissions[permission] = struct{}{}
+		}
+	}
+	return permissions
+}
@@ -0,0 +1,92 @@
+package authz
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ginx-contribs/ginx"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPolicy(t *testing.T) {
+	principal := &Principal{Subject: "jack", Roles: []string{"admin"}, Scopes: []string{"user.read"}}
+	ctx := evalContext{
+		principal:   principal,
+		roles:       Hierarchy{"admin": {"user"}}.expand(principal.Roles),
+		permissions: map[string]struct{}{"user.delete": {}},
+	}
+
+	samples := []struct {
+		expr string
+		want bool
+	}{
+		{"role:user", true},
+		{"role:guest", false},
+		{"role:guest || scope:user.read", true},
+		{"scope:user.read && !sub:jack", false},
+		{"(role:guest || perm:user.delete) && sub:*", true},
+	}
+	for _, sample := range samples {
+		expr, err := ParsePolicy(sample.expr)
+		if assert.NoError(t, err, sample.expr) {
+			assert.Equal(t, sample.want, expr.Eval(ctx), sample.expr)
+		}
+	}
+
+	for _, expr := range []string{"", "role:", "(role:a", "role:a &&", "group:a", "role:a | role:b"} {
+		_, err := ParsePolicy(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	server := ginx.New()
+	extractor := ExtractorFunc(func(ctx *gin.Context) (*Principal, error) {
+		if ctx.GetHeader("X-User") == "" {
+			return nil, nil
+		}
+		return &Principal{Subject: ctx.GetHeader("X-User"), Roles: []string{ctx.GetHeader("X-Role")}}, nil
+	})
+	server.Engine().Use(Authorize(
+		WithExtractor(extractor),
+		WithHierarchy(Hierarchy{"admin": {"user"}}),
+		WithRolePermissions(map[string][]string{"admin": {"user.delete"}}),
+	))
+
+	root := server.RouterGroup()
+	api := root.MGroup("api", ginx.M{Role.V("user")})
+	api.GET("/info", func(ctx *gin.Context) {})
+	api.MDELETE("/user", ginx.M{Permissions.V([]string{"user.delete"})}, func(ctx *gin.Context) {})
+	api.MGET("/ping", ginx.M{Public.V(true)}, func(ctx *gin.Context) {})
+	root.GET("/free", func(ctx *gin.Context) {})
+
+	samples := []struct {
+		method string
+		path   string
+		user   string
+		role   string
+		want   int
+	}{
+		{http.MethodGet, "/api/info", "", "", http.StatusUnauthorized},
+		{http.MethodGet, "/api/info", "jack", "guest", http.StatusForbidden},
+		{http.MethodGet, "/api/info", "jack", "admin", http.StatusOK},
+		{http.MethodDelete, "/api/user", "jack", "user", http.StatusForbidden},
+		{http.MethodDelete, "/api/user", "jack", "admin", http.StatusOK},
+		{http.MethodGet, "/api/ping", "", "", http.StatusOK},
+		{http.MethodGet, "/free", "", "", http.StatusOK},
+	}
+	for _, sample := range samples {
+		req := httptest.NewRequest(sample.method, sample.path, nil)
+		req.Header.Set("X-User", sample.user)
+		req.Header.Set("X-Role", sample.role)
+		recorder := httptest.NewRecorder()
+		server.Engine().ServeHTTP(recorder, req)
+		assert.Equal(t, sample.want, recorder.Code, "%s %s", sample.method, sample.path)
+	}
+
+	undeclared := Audit(server)
+	if assert.Len(t, undeclared, 1) {
+		assert.Equal(t, "/free", undeclared[0].FullPath)
+	}
+}
@@ -0,0 +1,214 @@
+package authz
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Expr is a compiled policy expression
+type Expr interface {
+	Eval(ctx evalContext) bool
+}
+
+type evalContext struct {
+	principal   *Principal
+	roles       map[string]struct{}
+	permissions map[string]struct{}
+}
+
+// compiled caches the compiled policy expressions, key is expression text.
+var compiled sync.Map
+
+func compilePolicy(expr string) (Expr, error) {
+	if e, ok := compiled.Load(expr); ok {
+		return e.(Expr), nil
+	}
+	e, err := ParsePolicy(expr)
+	if err != nil {
+		return nil, err
+	}
+	compiled.Store(expr, e)
+	return e, nil
+}
+
+// ParsePolicy parses the policy expression, which consists of terms combined by operators &&, ||, ! and parentheses.
+// Supported terms are:
+//
+//	role:<name>        principal has the role, include roles inherited by hierarchy
+//	scope:<name>       principal has the scope
+//	perm:<name>        principal has the permission, include permissions granted to its roles
+//	sub:<subject>      principal subject equals to the value
+//
+// Value * matches anything, e.g. "role:admin || (scope:user.read && !sub:guest)".
+func ParsePolicy(expr string) (Expr, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("policy %q: unexpected token %q", expr, p.tokens[p.pos])
+	}
+	return e, nil
+}
+
+func tokenize(expr string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '(' || c == ')' || c == '!':
+			tokens = append(tokens, string(c))
+			i++
+		case strings.HasPrefix(expr[i:], "&&"), strings.HasPrefix(expr[i:], "||"):
+			tokens = append(tokens, expr[i:i+2])
+			i += 2
+		case isTermChar(rune(c)):
+			j := i
+			for j < len(expr) && isTermChar(rune(expr[j])) {
+				j++
+			}
+			tokens = append(tokens, expr[i:j])
+			i = j
+		default:
+			return nil, fmt.Errorf("policy %q: invalid character %q at %d", expr, c, i)
+		}
+	}
+	return tokens, nil
+}
+
+func isTermChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune(":._-*/", r)
+}
+
+type parser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *parser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "||" {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orExpr{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "&&" {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andExpr{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	switch token := p.peek(); token {
+	case "!":
+		p.pos++
+		e, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{e}, nil
+	case "(":
+		p.pos++
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("missing ')'")
+		}
+		p.pos++
+		return e, nil
+	case "":
+		return nil, fmt.Errorf("unexpected end of policy")
+	default:
+		p.pos++
+		return parseTerm(token)
+	}
+}
+
+func parseTerm(token string) (Expr, error) {
+	kind, value, found := strings.Cut(token, ":")
+	if !found || value == "" {
+		return nil, fmt.Errorf("invalid term %q, expected form is kind:value", token)
+	}
+	switch kind {
+	case "role", "scope", "perm", "sub":
+		return termExpr{kind: kind, value: value}, nil
+	}
+	return nil, fmt.Errorf("unknown term kind %q", kind)
+}
+
+type andExpr struct{ left, right Expr }
+
+func (e andExpr) Eval(ctx evalContext) bool { return e.left.Eval(ctx) && e.right.Eval(ctx) }
+
+type orExpr struct{ left, right Expr }
+
+func (e orExpr) Eval(ctx evalContext) bool { return e.left.Eval(ctx) || e.right.Eval(ctx) }
+
+type notExpr struct{ e Expr }
+
+func (e notExpr) Eval(ctx evalContext) bool { return !e.e.Eval(ctx) }
+
+type termExpr struct {
+	kind  string
+	value string
+}
+
+func (e termExpr) Eval(ctx evalContext) bool {
+	switch e.kind {
+	case "role":
+		return matchSet(ctx.roles, e.value)
+	case "perm":
+		return matchSet(ctx.permissions, e.value)
+	case "scope":
+		return e.value == "*" && len(ctx.principal.Scopes) > 0 || slices.Contains(ctx.principal.Scopes, e.value)
+	case "sub":
+		return e.value == "*" && ctx.principal.Subject != "" || ctx.principal.Subject == e.value
+	}
+	return false
+}
+
+func matchSet(set map[string]struct{}, value string) bool {
+	if value == "*" {
+		return len(set) > 0
+	}
+	_, ok := set[value]
+	return ok
+}
@@ -0,0 +1,66 @@
+package authz
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+const principalKey = "github.com/ginx-contribs/ginx/contribs/authz.principal"
+
+// Principal is the identity who is making the request
+type Principal struct {
+	Subject     string
+	Roles       []string
+	Scopes      []string
+	Permissions []string
+}
+
+// PrincipalExtractor extracts principal from request, such as parsing token from header.
+type PrincipalExtractor interface {
+	Extract(ctx *gin.Context) (*Principal, error)
+}
+
+// ExtractorFunc is an adapter to use ordinary function as PrincipalExtractor
+type ExtractorFunc func(ctx *gin.Context) (*Principal, error)
+
+func (f ExtractorFunc) Extract(ctx *gin.Context) (*Principal, error) {
+	return f(ctx)
+}
+
+// SetPrincipal stores principal into context, it could be used by authentication handlers before Authorize.
+func SetPrincipal(ctx *gin.Context, principal *Principal) {
+	setPrincipal(ctx, principal)
+}
+
+func setPrincipal(ctx *gin.Context, principal *Principal) {
+	ctx.Set(principalKey, principal)
+}
+
+// FromCtx returns principal stored in context
+func FromCtx(ctx *gin.Context) (*Principal, bool) {
+	v, exists := ctx.Get(principalKey)
+	if !exists {
+		return nil, false
+	}
+	principal, ok := v.(*Principal)
+	return principal, ok
+}
+
+// Hierarchy declares roles inheritance, key is the role, value is the roles it inherits,
+// e.g. {"admin": {"user"}, "user": {"guest"}} means admin owns everything of user and guest.
+type Hierarchy map[string][]string
+
+// expand returns the given roles and all the roles they inherit
+func (h Hierarchy) expand(roles []string) map[string]struct{} {
+	expanded := make(map[string]struct{}, len(roles))
+	stack := append([]string(nil), roles...)
+	for len(stack) > 0 {
+		role := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if _, visited := expanded[role]; visited {
+			continue
+		}
+		expanded[role] = struct{}{}
+		stack = append(stack, h[role]...)
+	}
+	return expanded
+}
@@ -4,6 +4,7 @@ import (
 	gincache "github.com/chenyahui/gin-cache"
 	"github.com/chenyahui/gin-cache/persist"
 	"github.com/gin-gonic/gin"
+	"github.com/ginx-contribs/ginx/pkg/metrics"
 	"github.com/redis/go-redis/v9"
 	"time"
 )
@@ -25,6 +26,8 @@ type Options struct {
 	TTl       time.Duration
 	Store     persist.CacheStore
 	Strategy  gincache.GetCacheStrategyByRequest
+	// counts the cache hits and misses if not nil
+	Metrics *metrics.Registry
 }
 
 type Option func(options *Options)
@@ -59,6 +62,14 @@ func WithCacheOption(cacheOpts ...gincache.Option) Option {
 	}
 }
 
+// WithMetrics counts the requests into ginx_cache_requests_total labelled by route and result,
+// the result is hit or miss. It overrides the hit and miss callbacks in gin-cache options.
+func WithMetrics(registry *metrics.Registry) Option {
+	return func(options *Options) {
+		options.Metrics = registry
+	}
+}
+
 // Cache returns a cache handler
 func Cache(opts ...Option) gin.HandlerFunc {
 	var options Options
@@ -84,5 +95,28 @@ func Cache(opts ...Option) gin.HandlerFunc {
 
 	options.CacheOpts = append(options.CacheOpts, gincache.WithCacheStrategyByRequest(options.Strategy), gincache.WithPrefixKey(options.Prefix))
 
+	if options.Metrics != nil {
+		requests := options.Metrics.Counter("ginx_cache_requests_total", "Total number of requests checked by response cache.", "route", "result")
+		options.CacheOpts = append(options.CacheOpts,
+			gincache.WithOnHitCache(func(ctx *gin.Context) {
+				requests.Inc(metrics.Route(ctx), "hit")
+			}),
+			gincache.WithOnMissCache(func(ctx *gin.Context) {
+				requests.Inc(metrics.Route(ctx), "miss")
+			}),
+		)
+	}
+
 	return gincache.Cache(options.Store, options.TTl, options.CacheOpts...)
 }
+
+// Config is the declarative part of Options, it could be loaded from config files by pkg/config.
+type Config struct {
+	Prefix string        `mapstructure:"prefix"`
+	TTL    time.Duration `mapstructure:"ttl"`
+}
+
+// Options converts config to options of Cache
+func (c Config) Options() []Option {
+	return []Option{WithPrefix(c.Prefix), WithTTL(c.TTL)}
+}
@@ -5,6 +5,8 @@ import (
 	"github.com/ginx-contribs/ginx/constant/methods"
 	"github.com/ginx-contribs/ginx/constant/status"
 	"github.com/rs/cors"
+	"sync/atomic"
+	"time"
 )
 
 // Options is a configuration container to setup the CORS middleware.
@@ -46,3 +48,54 @@ func Default() gin.HandlerFunc {
 func New(options Options) gin.HandlerFunc {
 	return corsWrapper{cors.New(options), options.OptionsPassthrough}.build()
 }
+
+// Handler is a CORS middleware whose options could be updated at runtime
+type Handler struct {
+	handler atomic.Pointer[gin.HandlerFunc]
+}
+
+// NewHandler creates a CORS handler with the provided options, use Handle as gin middleware.
+func NewHandler(options Options) *Handler {
+	h := new(Handler)
+	h.Update(options)
+	return h
+}
+
+// Handle is the gin middleware
+func (h *Handler) Handle(ctx *gin.Context) {
+	(*h.handler.Load())(ctx)
+}
+
+// Update replaces the options, it is safe to call while serving
+func (h *Handler) Update(options Options) {
+	handler := New(options)
+	h.handler.Store(&handler)
+}
+
+// Config is the declarative part of Options, it could be loaded from config files by pkg/config.
+type Config struct {
+	AllowedOrigins       []string      `mapstructure:"allowedOrigins"`
+	AllowedMethods       []string      `mapstructure:"allowedMethods"`
+	AllowedHeaders       []string      `mapstructure:"allowedHeaders"`
+	ExposedHeaders       []string      `mapstructure:"exposedHeaders"`
+	MaxAge               time.Duration `mapstructure:"maxAge"`
+	AllowCredentials     bool          `mapstructure:"allowCredentials"`
+	AllowPrivateNetwork  bool          `mapstructure:"allowPrivateNetwork"`
+	OptionsPassthrough   bool          `mapstructure:"optionsPassthrough"`
+	OptionsSuccessStatus int           `mapstructure:"optionsSuccessStatus"`
+}
+
+// Options converts config to Options
+func (c Config) Options() Options {
+	return Options{
+		AllowedOrigins:       c.AllowedOrigins,
+		AllowedMethods:       c.AllowedMethods,
+		AllowedHeaders:       c.AllowedHeaders,
+		ExposedHeaders:       c.ExposedHeaders,
+		MaxAge:               int(c.MaxAge.Seconds()),
+		AllowCredentials:     c.AllowCredentials,
+		AllowPrivateNetwork:  c.AllowPrivateNetwork,
+		OptionsPassthrough:   c.OptionsPassthrough,
+		OptionsSuccessStatus: c.OptionsSuccessStatus,
+	}
+}
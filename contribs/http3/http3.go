@@ -0,0 +1,160 @@
+package http3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ginx-contribs/ginx"
+	"github.com/ginx-contribs/ginx/constant/headers"
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	ErrNoTLS     = errors.New("http3 requires tls to be configured")
+	ErrNoAddress = errors.New("no udp address to listen on")
+)
+
+type Options struct {
+	// UDP address to listen on, default is the address of the first TCP listener of server
+	Address string
+	// QUIC transport config
+	QUICConfig *quic.Config
+	// max-age of Alt-Svc header, default is 24 hours
+	MaxAge time.Duration
+	// do not advertise HTTP/3 by Alt-Svc header
+	DisableAltSvc bool
+}
+
+type Option func(options *Options)
+
+func WithAddress(address string) Option {
+	return func(options *Options) {
+		options.Address = address
+	}
+}
+
+func WithQUICConfig(config *quic.Config) Option {
+	return func(options *Options) {
+		options.QUICConfig = config
+	}
+}
+
+func WithMaxAge(maxAge time.Duration) Option {
+	return func(options *Options) {
+		options.MaxAge = maxAge
+	}
+}
+
+func WithoutAltSvc() Option {
+	return func(options *Options) {
+		options.DisableAltSvc = true
+	}
+}
+
+// Enable returns a ginx option which serves the engine over QUIC alongside TLS, it shares the tls config
+// and certificates of server, so certificates reloading also takes effect on HTTP/3.
+// The HTTP/3 server starts listening in after started hooks, and shuts down gracefully in on shutdown hooks,
+// so hooks registered before it can not reach HTTP/3. The UDP socket is not passed to new process on graceful restart.
+func Enable(opts ...Option) ginx.Option {
+	var options Options
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if options.MaxAge == 0 {
+		options.MaxAge = time.Hour * 24
+	}
+
+	return func(server *ginx.Server) {
+		s := &quicServer{server: server, options: options}
+		ginx.WithBeforeStarting(s.advertise)(server)
+		ginx.WithAfterStarted(s.start)(server)
+		ginx.WithOnShutdown(s.shutdown)(server)
+	}
+}
+
+type quicServer struct {
+	server  *ginx.Server
+	options Options
+
+	h3     *http3.Server
+	conn   net.PacketConn
+	altSvc atomic.Pointer[string]
+}
+
+// advertise adds Alt-Svc header into responses over TLS, it is empty until HTTP/3 server starts
+func (s *quicServer) advertise(_ context.Context) error {
+	if s.options.DisableAltSvc {
+		return nil
+	}
+	httpserver := s.server.HttpServer()
+	next := httpserver.Handler
+	httpserver.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if altSvc := s.altSvc.Load(); altSvc != nil && r.TLS != nil {
+			w.Header().Add(headers.AltSvc, *altSvc)
+		}
+		next.ServeHTTP(w, r)
+	})
+	return nil
+}
+
+func (s *quicServer) start(ctx context.Context) error {
+	config := s.server.TLSConfig()
+	if config == nil {
+		return ErrNoTLS
+	}
+
+	address := s.options.Address
+	if address == "" {
+		for _, addr := range ginx.AddrsFromCtx(ctx) {
+			if tcpAddr, ok := addr.(*net.TCPAddr); ok {
+				address = tcpAddr.String()
+				break
+			}
+		}
+	}
+	if address == "" {
+		return ErrNoAddress
+	}
+
+	conn, err := net.ListenPacket("udp", address)
+	if err != nil {
+		return err
+	}
+	httpserver := s.server.HttpServer()
+	s.conn = conn
+	s.h3 = &http3.Server{
+		Handler:        s.server.Engine(),
+		TLSConfig:      config,
+		QUICConfig:     s.options.QUICConfig,
+		MaxHeaderBytes: httpserver.MaxHeaderBytes,
+		IdleTimeout:    httpserver.IdleTimeout,
+	}
+
+	slog.InfoContext(ctx, fmt.Sprintf("http3 server is listening at udp://%s", conn.LocalAddr()))
+	go func() {
+		if err := s.h3.Serve(conn); err != nil && !errors.Is(err, http.ErrServerClosed) && !errors.Is(err, quic.ErrServerClosed) {
+			slog.ErrorContext(ctx, "http3 server failed", slog.Any("error", err))
+		}
+	}()
+
+	altSvc := fmt.Sprintf(`%s=":%d"; ma=%d`, http3.NextProtoH3, conn.LocalAddr().(*net.UDPAddr).Port, int(s.options.MaxAge.Seconds()))
+	s.altSvc.Store(&altSvc)
+	return nil
+}
+
+func (s *quicServer) shutdown(ctx context.Context) error {
+	// stop advertising
+	s.altSvc.Store(nil)
+	if s.h3 == nil {
+		return nil
+	}
+	err := s.h3.Shutdown(ctx)
+	return errors.Join(err, s.conn.Close())
+}
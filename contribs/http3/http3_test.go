@@ -0,0 +1,100 @@
+package http3
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/ginx-contribs/ginx"
+	"github.com/ginx-contribs/ginx/constant/headers"
+	"github.com/ginx-contribs/ginx/constant/protocols"
+	"github.com/quic-go/quic-go/http3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeCert(t *testing.T, dir string) (certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile, keyFile = filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600))
+	return certFile, keyFile
+}
+
+func TestHTTP3(t *testing.T) {
+	certFile, keyFile := writeCert(t, t.TempDir())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var altSvc, body string
+	server := ginx.New(
+		ginx.WithCtx(ctx),
+		ginx.WithAddress("127.0.0.1:0"),
+		ginx.WithTLS(keyFile, certFile),
+		Enable(WithMaxAge(time.Hour)),
+		ginx.WithAfterStarted(func(ctx context.Context) error {
+			defer cancel()
+			addr := ginx.AddrsFromCtx(ctx)[0].(*net.TCPAddr)
+			tlsConfig := &tls.Config{InsecureSkipVerify: true}
+
+			client := http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+			res, err := client.Get(fmt.Sprintf("https://%s/ping", addr))
+			if err != nil {
+				return err
+			}
+			res.Body.Close()
+			altSvc = res.Header.Get(headers.AltSvc)
+
+			transport := &http3.Transport{TLSClientConfig: tlsConfig}
+			defer transport.Close()
+			client = http.Client{Transport: transport}
+			res, err = client.Get(fmt.Sprintf("https://%s/ping", addr))
+			if err != nil {
+				return err
+			}
+			defer res.Body.Close()
+			data, err := io.ReadAll(res.Body)
+			body = string(data)
+			return err
+		}),
+	)
+	server.RouterGroup().GET("/ping", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, ctx.Request.Proto)
+	})
+
+	require.NoError(t, server.Spin())
+	port := server.Addrs()[0].(*net.TCPAddr).Port
+	assert.Equal(t, fmt.Sprintf(`h3=":%d"; ma=3600`, port), altSvc)
+	assert.Equal(t, protocols.HTTP30, body)
+}
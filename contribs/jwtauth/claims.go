@@ -0,0 +1,155 @@
+package jwtauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"slices"
+	"time"
+)
+
+const claimsKey = "github.com/ginx-contribs/ginx/contribs/jwtauth.claims"
+
+type claimsCtxKey struct{}
+
+// Claims is the verified claims of token, registered claims are parsed into fields,
+// and all the claims include the registered are kept in Raw.
+type Claims struct {
+	Issuer    string
+	Subject   string
+	Audience  []string
+	ExpiresAt time.Time
+	NotBefore time.Time
+	IssuedAt  time.Time
+	ID        string
+
+	Raw map[string]any
+}
+
+// Get returns the raw value of claim
+func (c *Claims) Get(name string) (any, bool) {
+	v, ok := c.Raw[name]
+	return v, ok
+}
+
+// String returns the claim value if it is a string
+func (c *Claims) String(name string) string {
+	s, _ := c.Raw[name].(string)
+	return s
+}
+
+// Strings returns the claim value as string slice, a single string is treated as slice with one element.
+func (c *Claims) Strings(name string) []string {
+	switch v := c.Raw[name].(type) {
+	case string:
+		return []string{v}
+	case []any:
+		ss := make([]string, 0, len(v))
+		for _, e := range v {
+			if s, ok := e.(string); ok {
+				ss = append(ss, s)
+			}
+		}
+		return ss
+	}
+	return nil
+}
+
+// Decode decodes the raw claims into the custom struct
+func (c *Claims) Decode(v any) error {
+	bytes, err := json.Marshal(c.Raw)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(bytes, v)
+}
+
+func parseClaims(payload []byte) (*Claims, error) {
+	claims := &Claims{}
+	if err := json.Unmarshal(payload, &claims.Raw); err != nil {
+		return nil, fmt.Errorf("%w: malformed claims: %w", ErrInvalidToken, err)
+	}
+
+	var err error
+	claims.Issuer = claims.String("iss")
+	claims.Subject = claims.String("sub")
+	claims.ID = claims.String("jti")
+	claims.Audience = claims.Strings("aud")
+	if claims.ExpiresAt, err = numericDate(claims.Raw, "exp"); err != nil {
+		return nil, err
+	}
+	if claims.NotBefore, err = numericDate(claims.Raw, "nbf"); err != nil {
+		return nil, err
+	}
+	if claims.IssuedAt, err = numericDate(claims.Raw, "iat"); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// numericDate parses the seconds since epoch
+func numericDate(raw map[string]any, name string) (time.Time, error) {
+	v, ok := raw[name]
+	if !ok {
+		return time.Time{}, nil
+	}
+	f, ok := v.(float64)
+	if !ok {
+		return time.Time{}, fmt.Errorf("%w: claim %s is not a numeric date", ErrInvalidToken, name)
+	}
+	return time.Unix(0, int64(f*float64(time.Second))), nil
+}
+
+var (
+	ErrTokenExpired     = errors.New("token is expired")
+	ErrTokenNotValidYet = errors.New("token is not valid yet")
+	ErrInvalidIssuer    = errors.New("invalid issuer")
+	ErrInvalidAudience  = errors.New("invalid audience")
+)
+
+// validate checks the registered claims
+func (c *Claims) validate(options *Options, now time.Time) error {
+	if !c.ExpiresAt.IsZero() && !now.Before(c.ExpiresAt.Add(options.Leeway)) {
+		return ErrTokenExpired
+	} else if c.ExpiresAt.IsZero() && options.RequireExp {
+		return fmt.Errorf("%w: missing exp", ErrInvalidToken)
+	}
+
+	if !c.NotBefore.IsZero() && now.Add(options.Leeway).Before(c.NotBefore) {
+		return ErrTokenNotValidYet
+	}
+
+	if len(options.Issuers) > 0 && !slices.Contains(options.Issuers, c.Issuer) {
+		return fmt.Errorf("%w: %s", ErrInvalidIssuer, c.Issuer)
+	}
+
+	if len(options.Audiences) > 0 && !slices.ContainsFunc(c.Audience, func(aud string) bool {
+		return slices.Contains(options.Audiences, aud)
+	}) {
+		return fmt.Errorf("%w: %v", ErrInvalidAudience, c.Audience)
+	}
+	return nil
+}
+
+// FromCtx returns the verified claims from context, both *gin.Context and the request context are accepted.
+func FromCtx(ctx context.Context) (*Claims, bool) {
+	if ginCtx, ok := ctx.(*gin.Context); ok {
+		if v, exists := ginCtx.Get(claimsKey); exists {
+			claims, ok := v.(*Claims)
+			return claims, ok
+		}
+		if ginCtx.Request == nil {
+			return nil, false
+		}
+		ctx = ginCtx.Request.Context()
+	}
+	claims, ok := ctx.Value(claimsCtxKey{}).(*Claims)
+	return claims, ok
+}
+
+func withClaims(ctx *gin.Context, claims *Claims) {
+	ctx.Set(claimsKey, claims)
+	ctx.Request = ctx.Request.WithContext(context.WithValue(ctx.Request.Context(), claimsCtxKey{}, claims))
+}
@@ -0,0 +1,203 @@
+package jwtauth
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/ginx-contribs/ginx"
+	"github.com/ginx-contribs/ginx/constant/headers"
+	"github.com/ginx-contribs/ginx/pkg/resp"
+	"github.com/ginx-contribs/ginx/pkg/resp/statuserr"
+	"strings"
+	"time"
+)
+
+// Public marks the route can be accessed without token, it shares the same key with authz.Public.
+var Public = ginx.NewKey[bool]("public")
+
+var ErrMissingToken = errors.New("missing bearer token")
+
+type Options struct {
+	// where to find the verification keys
+	KeySet KeySet
+	// allowed algorithms, default are HS256, RS256, ES256 and EdDSA
+	Algorithms []string
+	// allowed issuers, empty means no check
+	Issuers []string
+	// allowed audiences, token should have any of them, empty means no check
+	Audiences []string
+	// tolerance of clock skew when checking exp and nbf
+	Leeway time.Duration
+	// reject token without exp claim
+	RequireExp bool
+	// realm in WWW-Authenticate header
+	Realm string
+
+	// extract token from request, default is reading bearer token from Authorization header
+	TokenLookup func(ctx *gin.Context) string
+	// skip authentication if returns true, default skips the routes marked by Public
+	Skip         func(ctx *gin.Context) bool
+	ErrorHandler func(ctx *gin.Context, err error)
+
+	now func() time.Time
+}
+
+type Option func(options *Options)
+
+func WithKeySet(keySet KeySet) Option {
+	return func(options *Options) {
+		options.KeySet = keySet
+	}
+}
+
+func WithKeys(keys ...Key) Option {
+	return func(options *Options) {
+		options.KeySet = StaticKeys(keys)
+	}
+}
+
+func WithAlgorithms(algorithms ...string) Option {
+	return func(options *Options) {
+		options.Algorithms = algorithms
+	}
+}
+
+func WithIssuers(issuers ...string) Option {
+	return func(options *Options) {
+		options.Issuers = issuers
+	}
+}
+
+func WithAudiences(audiences ...string) Option {
+	return func(options *Options) {
+		options.Audiences = audiences
+	}
+}
+
+func WithLeeway(leeway time.Duration) Option {
+	return func(options *Options) {
+		options.Leeway = leeway
+	}
+}
+
+func WithRequireExp(require bool) Option {
+	return func(options *Options) {
+		options.RequireExp = require
+	}
+}
+
+func WithRealm(realm string) Option {
+	return func(options *Options) {
+		options.Realm = realm
+	}
+}
+
+func WithTokenLookup(lookup func(ctx *gin.Context) string) Option {
+	return func(options *Options) {
+		options.TokenLookup = lookup
+	}
+}
+
+func WithSkip(skip func(ctx *gin.Context) bool) Option {
+	return func(options *Options) {
+		options.Skip = skip
+	}
+}
+
+func WithErrorHandler(handler func(ctx *gin.Context, err error)) Option {
+	return func(options *Options) {
+		options.ErrorHandler = handler
+	}
+}
+
+// JWTAuth returns a handler which verifies the bearer token, then stores the claims into both gin context
+// and request context, use FromCtx to get it. It panics if no KeySet is provided.
+func JWTAuth(opts ...Option) gin.HandlerFunc {
+	var options Options
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if options.KeySet == nil {
+		panic("jwtauth: KeySet is required")
+	}
+
+	if len(options.Algorithms) == 0 {
+		options.Algorithms = []string{HS256, RS256, ES256, EdDSA}
+	}
+
+	if options.TokenLookup == nil {
+		options.TokenLookup = BearerToken
+	}
+
+	if options.Skip == nil {
+		options.Skip = func(ctx *gin.Context) bool {
+			public, _ := Public.FromCtx(ctx)
+			return public
+		}
+	}
+
+	if options.ErrorHandler == nil {
+		options.ErrorHandler = func(ctx *gin.Context, err error) {
+			ctx.Header(headers.WWWAuthenticate, challenge(options.Realm, err))
+			resp.Fail(ctx).Error(statuserr.UnAuthorized(err)).JSON()
+			ctx.Abort()
+		}
+	}
+
+	if options.now == nil {
+		options.now = time.Now
+	}
+
+	return func(ctx *gin.Context) {
+		if options.Skip(ctx) {
+			ctx.Next()
+			return
+		}
+
+		token := options.TokenLookup(ctx)
+		if token == "" {
+			options.ErrorHandler(ctx, ErrMissingToken)
+			return
+		}
+
+		claims, err := verify(token, options.KeySet, options.Algorithms)
+		if err == nil {
+			err = claims.validate(&options, options.now())
+		}
+		if err != nil {
+			options.ErrorHandler(ctx, err)
+			return
+		}
+
+		withClaims(ctx, claims)
+		ctx.Next()
+	}
+}
+
+// BearerToken reads the bearer token from Authorization header
+func BearerToken(ctx *gin.Context) string {
+	auth := ctx.GetHeader(headers.Authorization)
+	scheme, token, found := strings.Cut(auth, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// challenge builds WWW-Authenticate header value, see RFC 6750 section 3
+func challenge(realm string, err error) string {
+	var params []string
+	if realm != "" {
+		params = append(params, fmt.Sprintf("realm=%q", realm))
+	}
+	// no error code if request lacks authentication information
+	if !errors.Is(err, ErrMissingToken) {
+		desc := strings.ReplaceAll(err.Error(), `"`, `'`)
+		params = append(params, `error="invalid_token"`, fmt.Sprintf("error_description=%q", desc))
+	}
+	if len(params) == 0 {
+		return "Bearer"
+	}
+	return "Bearer " + strings.Join(params, ", ")
+}
@@ -0,0 +1,185 @@
+package jwtauth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/ginx-contribs/ginx"
+	"github.com/ginx-contribs/ginx/constant/headers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func sign(t *testing.T, alg, kid string, key any, claims map[string]any) string {
+	h, err := json.Marshal(header{Alg: alg, Kid: kid, Typ: "JWT"})
+	require.NoError(t, err)
+	c, err := json.Marshal(claims)
+	require.NoError(t, err)
+	signed := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(c)
+
+	digest := sha256.Sum256([]byte(signed))
+	var signature []byte
+	switch alg {
+	case HS256:
+		mac := hmac.New(sha256.New, key.([]byte))
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+	case RS256:
+		signature, err = rsa.SignPKCS1v15(rand.Reader, key.(*rsa.PrivateKey), crypto.SHA256, digest[:])
+	case ES256:
+		r, s, e := ecdsa.Sign(rand.Reader, key.(*ecdsa.PrivateKey), digest[:])
+		signature, err = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...), e
+	case EdDSA:
+		signature = ed25519.Sign(key.(ed25519.PrivateKey), []byte(signed))
+	}
+	require.NoError(t, err)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestVerify(t *testing.T) {
+	secret := []byte("secret")
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	keys := StaticKeys{
+		HMACKey("hs", secret),
+		{ID: "rs", Key: &rsaKey.PublicKey},
+		{ID: "es", Key: &ecKey.PublicKey},
+		{ID: "ed", Key: edPub},
+	}
+	algorithms := []string{HS256, RS256, ES256, EdDSA}
+	claims := map[string]any{"sub": "jack", "aud": "api"}
+
+	samples := []struct {
+		alg string
+		kid string
+		key any
+	}{
+		{HS256, "hs", secret},
+		{RS256, "rs", rsaKey},
+		{ES256, "es", ecKey},
+		{EdDSA, "ed", edKey},
+		// without kid, all keys accepting the algorithm are tried
+		{RS256, "", rsaKey},
+	}
+	for _, sample := range samples {
+		c, err := verify(sign(t, sample.alg, sample.kid, sample.key, claims), keys, algorithms)
+		if assert.NoError(t, err, sample.alg) {
+			assert.Equal(t, "jack", c.Subject)
+			assert.Equal(t, []string{"api"}, c.Audience)
+		}
+	}
+
+	_, err = verify(sign(t, HS256, "hs", []byte("wrong"), claims), keys, algorithms)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+	_, err = verify(sign(t, HS256, "unknown", secret, claims), keys, algorithms)
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	_, err = verify(sign(t, HS256, "hs", secret, claims), keys, []string{RS256})
+	assert.ErrorIs(t, err, ErrUnsupportedAlg)
+	_, err = verify("a.b", keys, algorithms)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestJWKS(t *testing.T) {
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	write := func(keys ...map[string]string) {
+		data, err := json.Marshal(map[string]any{"keys": keys})
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, data, 0o644))
+	}
+	encode := base64.RawURLEncoding.EncodeToString
+	edJWK := map[string]string{"kty": "OKP", "crv": "Ed25519", "kid": "ed", "x": encode(edPub)}
+	ecJWK := map[string]string{"kty": "EC", "crv": "P-256", "kid": "es",
+		"x": encode(ecKey.X.FillBytes(make([]byte, 32))), "y": encode(ecKey.Y.FillBytes(make([]byte, 32)))}
+
+	write(edJWK)
+	jwks, err := LoadJWKS(path, time.Millisecond)
+	require.NoError(t, err)
+	assert.Len(t, jwks.Keys(), 1)
+
+	algorithms := []string{ES256, EdDSA}
+	token := sign(t, ES256, "es", ecKey, map[string]any{})
+	_, err = verify(token, jwks, algorithms)
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	// rotate keys
+	write(edJWK, ecJWK)
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Second)))
+	time.Sleep(2 * time.Millisecond)
+	_, err = verify(token, jwks, algorithms)
+	assert.NoError(t, err)
+	_, err = verify(sign(t, EdDSA, "ed", edKey, map[string]any{}), jwks, algorithms)
+	assert.NoError(t, err)
+}
+
+func TestJWTAuth(t *testing.T) {
+	secret := []byte("secret")
+	server := ginx.New()
+	server.Engine().Use(JWTAuth(
+		WithKeys(HMACKey("", secret)),
+		WithIssuers("ginx"),
+		WithRealm("test"),
+	))
+	root := server.RouterGroup()
+	root.GET("/me", func(ctx *gin.Context) {
+		claims, ok := FromCtx(ctx.Request.Context())
+		if assert.True(t, ok) {
+			ctx.String(http.StatusOK, claims.Subject)
+		}
+	})
+	root.MGET("/ping", ginx.M{Public.V(true)}, func(ctx *gin.Context) {})
+
+	now := time.Now().Unix()
+	samples := []struct {
+		path      string
+		token     string
+		want      int
+		challenge string
+	}{
+		{"/me", "", http.StatusUnauthorized, `Bearer realm="test"`},
+		{"/me", sign(t, HS256, "", secret, map[string]any{"iss": "ginx", "sub": "jack", "exp": now + 60}), http.StatusOK, ""},
+		{"/me", sign(t, HS256, "", secret, map[string]any{"iss": "ginx", "exp": now - 60}), http.StatusUnauthorized,
+			`Bearer realm="test", error="invalid_token", error_description="token is expired"`},
+		{"/me", sign(t, HS256, "", secret, map[string]any{"iss": "other"}), http.StatusUnauthorized,
+			`Bearer realm="test", error="invalid_token", error_description="invalid issuer: other"`},
+		{"/me", sign(t, HS256, "", secret, map[string]any{"iss": "ginx", "nbf": now + 60}), http.StatusUnauthorized,
+			`Bearer realm="test", error="invalid_token", error_description="token is not valid yet"`},
+		{"/ping", "", http.StatusOK, ""},
+	}
+	for _, sample := range samples {
+		req := httptest.NewRequest(http.MethodGet, sample.path, nil)
+		if sample.token != "" {
+			req.Header.Set(headers.Authorization, "Bearer "+sample.token)
+		}
+		recorder := httptest.NewRecorder()
+		server.Engine().ServeHTTP(recorder, req)
+		assert.Equal(t, sample.want, recorder.Code)
+		assert.Equal(t, sample.challenge, recorder.Header().Get(headers.WWWAuthenticate))
+		if sample.want == http.StatusOK && sample.path == "/me" {
+			assert.Equal(t, "jack", recorder.Body.String())
+		}
+	}
+}
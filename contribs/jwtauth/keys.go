@@ -0,0 +1,269 @@
+package jwtauth
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+)
+
+// supported algorithms
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	ES256 = "ES256"
+	EdDSA = "EdDSA"
+)
+
+// Key is a verification key, Key.Key should be one of []byte for HS256, *rsa.PublicKey for RS256,
+// *ecdsa.PublicKey for ES256 and ed25519.PublicKey for EdDSA.
+type Key struct {
+	// key id, it is matched with kid in token header
+	ID string
+	// the algorithm the key is used for, empty means any algorithm which matches the key type
+	Algorithm string
+	Key       any
+}
+
+// accept reports whether the key can verify the token signed by alg
+func (k Key) accept(alg string) bool {
+	if k.Algorithm != "" && k.Algorithm != alg {
+		return false
+	}
+	switch k.Key.(type) {
+	case []byte:
+		return alg == HS256
+	case *rsa.PublicKey:
+		return alg == RS256
+	case *ecdsa.PublicKey:
+		return alg == ES256
+	case ed25519.PublicKey:
+		return alg == EdDSA
+	}
+	return false
+}
+
+// KeySet provides verification keys, keys with different id could be used at the same time for key rotation.
+type KeySet interface {
+	Keys() []Key
+}
+
+// StaticKeys is a fixed KeySet
+type StaticKeys []Key
+
+func (s StaticKeys) Keys() []Key {
+	return s
+}
+
+// HMACKey returns a HS256 key with the secret
+func HMACKey(id string, secret []byte) Key {
+	return Key{ID: id, Algorithm: HS256, Key: secret}
+}
+
+// ParsePEM parses a PEM encoded public key or certificate, supports PKIX public key and x509 certificate.
+func ParsePEM(id string, data []byte) (Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return Key{}, errors.New("no pem block found")
+	}
+
+	var (
+		pub any
+		err error
+	)
+	switch block.Type {
+	case "CERTIFICATE":
+		var cert *x509.Certificate
+		cert, err = x509.ParseCertificate(block.Bytes)
+		if err == nil {
+			pub = cert.PublicKey
+		}
+	case "RSA PUBLIC KEY":
+		pub, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		pub, err = x509.ParsePKIXPublicKey(block.Bytes)
+	}
+	if err != nil {
+		return Key{}, err
+	}
+
+	key := Key{ID: id, Key: pub}
+	if !key.accept(RS256) && !key.accept(ES256) && !key.accept(EdDSA) {
+		return Key{}, fmt.Errorf("unsupported public key type %T", pub)
+	}
+	return key, nil
+}
+
+// jwk is the json web key, see RFC 7517
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	K   string `json:"k"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (j jwk) key() (any, error) {
+	switch j.Kty {
+	case "oct":
+		return decodeSegment(j.K)
+	case "RSA":
+		n, err := decodeSegment(j.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeSegment(j.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		if j.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %s", j.Crv)
+		}
+		x, err := decodeSegment(j.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeSegment(j.Y)
+		if err != nil {
+			return nil, err
+		}
+		pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if _, err := pub.ECDH(); err != nil {
+			return nil, err
+		}
+		return pub, nil
+	case "OKP":
+		if j.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %s", j.Crv)
+		}
+		x, err := decodeSegment(j.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid ed25519 public key size")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("unsupported key type %s", j.Kty)
+}
+
+// ParseJWKS parses the JSON Web Key Set document, keys which are not used for signature are ignored.
+func ParseJWKS(data []byte) ([]Key, error) {
+	var doc struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	keys := make([]Key, 0, len(doc.Keys))
+	for i, jwk := range doc.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		pub, err := jwk.key()
+		if err != nil {
+			return nil, fmt.Errorf("key %d %q: %w", i, jwk.Kid, err)
+		}
+		keys = append(keys, Key{ID: jwk.Kid, Algorithm: jwk.Alg, Key: pub})
+	}
+	return keys, nil
+}
+
+// JWKS is a KeySet loaded from JWKS document on disk, the file will be checked at most once per interval,
+// and reloaded if it has been modified. If reloading fails, the previous keys are kept.
+type JWKS struct {
+	path     string
+	interval time.Duration
+
+	mu      sync.RWMutex
+	keys    []Key
+	modTime time.Time
+	checked time.Time
+}
+
+// LoadJWKS loads JWKS file, interval <= 0 disables the periodic reload.
+func LoadJWKS(path string, interval time.Duration) (*JWKS, error) {
+	jwks := &JWKS{path: path, interval: interval}
+	if err := jwks.Reload(); err != nil {
+		return nil, err
+	}
+	return jwks, nil
+}
+
+func (j *JWKS) Keys() []Key {
+	j.mu.RLock()
+	keys, checked := j.keys, j.checked
+	j.mu.RUnlock()
+
+	if j.interval > 0 && time.Since(checked) >= j.interval {
+		if err := j.refresh(); err != nil {
+			slog.Warn("failed to reload jwks", slog.String("path", j.path), slog.Any("error", err))
+		}
+		j.mu.RLock()
+		keys = j.keys
+		j.mu.RUnlock()
+	}
+	return keys
+}
+
+// Reload reads the file and replace the keys
+func (j *JWKS) Reload() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	info, err := os.Stat(j.path)
+	if err != nil {
+		return err
+	}
+	return j.load(info.ModTime())
+}
+
+// refresh reloads the file only if it has been modified since last loading
+func (j *JWKS) refresh() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	// double check, other goroutine may have checked
+	if time.Since(j.checked) < j.interval {
+		return nil
+	}
+	j.checked = time.Now()
+	info, err := os.Stat(j.path)
+	if err != nil {
+		return err
+	}
+	if info.ModTime().Equal(j.modTime) {
+		return nil
+	}
+	return j.load(info.ModTime())
+}
+
+func (j *JWKS) load(modTime time.Time) error {
+	data, err := os.ReadFile(j.path)
+	if err != nil {
+		return err
+	}
+	keys, err := ParseJWKS(data)
+	if err != nil {
+		return err
+	}
+	j.keys, j.modTime, j.checked = keys, modTime, time.Now()
+	return nil
+}
@@ -0,0 +1,116 @@
+package jwtauth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+var (
+	ErrInvalidToken     = errors.New("invalid token")
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrUnsupportedAlg   = errors.New("unsupported algorithm")
+	ErrKeyNotFound      = errors.New("no key found for token")
+)
+
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Typ string `json:"typ"`
+}
+
+// verify verifies the compact serialized token signature with keys, returns the claims if succeed.
+func verify(token string, keySet KeySet, algorithms []string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: token should have 3 segments", ErrInvalidToken)
+	}
+
+	headerBytes, err := decodeSegment(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed header: %w", ErrInvalidToken, err)
+	}
+	var h header
+	if err := json.Unmarshal(headerBytes, &h); err != nil {
+		return nil, fmt.Errorf("%w: malformed header: %w", ErrInvalidToken, err)
+	}
+
+	if !isAllowed(h.Alg, algorithms) {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedAlg, h.Alg)
+	}
+
+	signature, err := decodeSegment(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature: %w", ErrInvalidToken, err)
+	}
+
+	signed := []byte(token[:len(parts[0])+1+len(parts[1])])
+	found := false
+	for _, key := range keySet.Keys() {
+		if (h.Kid != "" && key.ID != h.Kid) || !key.accept(h.Alg) {
+			continue
+		}
+		found = true
+		if verifySignature(h.Alg, key.Key, signed, signature) {
+			payload, err := decodeSegment(parts[1])
+			if err != nil {
+				return nil, fmt.Errorf("%w: malformed claims: %w", ErrInvalidToken, err)
+			}
+			return parseClaims(payload)
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf("%w: kid %q alg %s", ErrKeyNotFound, h.Kid, h.Alg)
+	}
+	return nil, ErrInvalidSignature
+}
+
+func isAllowed(alg string, algorithms []string) bool {
+	// none algorithm is never allowed
+	if alg == "" || strings.EqualFold(alg, "none") {
+		return false
+	}
+	for _, a := range algorithms {
+		if a == alg {
+			return true
+		}
+	}
+	return false
+}
+
+func verifySignature(alg string, key any, signed, signature []byte) bool {
+	switch alg {
+	case HS256:
+		mac := hmac.New(sha256.New, key.([]byte))
+		mac.Write(signed)
+		return hmac.Equal(signature, mac.Sum(nil))
+	case RS256:
+		digest := sha256.Sum256(signed)
+		return rsa.VerifyPKCS1v15(key.(*rsa.PublicKey), crypto.SHA256, digest[:], signature) == nil
+	case ES256:
+		// signature is r || s with fixed length, see RFC 7518 section 3.4
+		if len(signature) != 64 {
+			return false
+		}
+		digest := sha256.Sum256(signed)
+		r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+		return ecdsa.Verify(key.(*ecdsa.PublicKey), digest[:], r, s)
+	case EdDSA:
+		return ed25519.Verify(key.(ed25519.PublicKey), signed, signature)
+	}
+	return false
+}
+
+func decodeSegment(seg string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(seg, "="))
+}
@@ -0,0 +1,114 @@
+package metrics
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ginx-contribs/ginx"
+	pkgmetrics "github.com/ginx-contribs/ginx/pkg/metrics"
+	"time"
+)
+
+// Labels declares the values of extra labels for the route, the label names should be declared by WithLabels,
+// the undeclared names are ignored, and the missing values are empty.
+var Labels = ginx.NewKey[map[string]string]("metrics.labels")
+
+// Skip opts the route out of metrics
+var Skip = ginx.NewKey[bool]("metrics.skip")
+
+type Options struct {
+	// registry to collect into, default is pkg/metrics.Default
+	Registry *pkgmetrics.Registry
+	// names of extra labels, whose values come from route metadata Labels
+	Labels []string
+	// buckets of request duration in seconds
+	DurationBuckets []float64
+	// buckets of request and response size in bytes
+	SizeBuckets []float64
+}
+
+type Option func(options *Options)
+
+func WithRegistry(registry *pkgmetrics.Registry) Option {
+	return func(options *Options) {
+		options.Registry = registry
+	}
+}
+
+func WithLabels(labels ...string) Option {
+	return func(options *Options) {
+		options.Labels = labels
+	}
+}
+
+func WithDurationBuckets(buckets ...float64) Option {
+	return func(options *Options) {
+		options.DurationBuckets = buckets
+	}
+}
+
+func WithSizeBuckets(buckets ...float64) Option {
+	return func(options *Options) {
+		options.SizeBuckets = buckets
+	}
+}
+
+// Metrics returns a handler which collects request metrics labelled by method, route template and status class:
+//
+//	ginx_http_requests_total              counter
+//	ginx_http_request_duration_seconds    histogram
+//	ginx_http_requests_in_flight          gauge, without status label
+//	ginx_http_request_size_bytes          histogram
+//	ginx_http_response_size_bytes         histogram
+func Metrics(opts ...Option) gin.HandlerFunc {
+	var options Options
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if options.Registry == nil {
+		options.Registry = pkgmetrics.Default
+	}
+
+	if len(options.SizeBuckets) == 0 {
+		options.SizeBuckets = pkgmetrics.SizeBuckets
+	}
+
+	registry := options.Registry
+	labels := append([]string{"method", "route", "status"}, options.Labels...)
+	inflightLabels := append([]string{"method", "route"}, options.Labels...)
+
+	requests := registry.Counter("ginx_http_requests_total", "Total number of HTTP requests.", labels...)
+	duration := registry.Histogram("ginx_http_request_duration_seconds", "Duration of HTTP requests in seconds.", options.DurationBuckets, labels...)
+	inflight := registry.Gauge("ginx_http_requests_in_flight", "Number of HTTP requests being served.", inflightLabels...)
+	requestSize := registry.Histogram("ginx_http_request_size_bytes", "Size of HTTP requests in bytes.", options.SizeBuckets, labels...)
+	responseSize := registry.Histogram("ginx_http_response_size_bytes", "Size of HTTP responses in bytes.", options.SizeBuckets, labels...)
+
+	return func(ctx *gin.Context) {
+		if skip, _ := Skip.FromCtx(ctx); skip {
+			ctx.Next()
+			return
+		}
+
+		begin := time.Now()
+		values := make([]string, len(labels))
+		values[0] = ctx.Request.Method
+		values[1] = pkgmetrics.Route(ctx)
+		if len(options.Labels) > 0 {
+			extra, _ := Labels.FromCtx(ctx)
+			for i, name := range options.Labels {
+				values[3+i] = extra[name]
+			}
+		}
+		inflightValues := append([]string{values[0], values[1]}, values[3:]...)
+
+		inflight.Inc(inflightValues...)
+		defer inflight.Dec(inflightValues...)
+
+		ctx.Next()
+
+		values[2] = pkgmetrics.StatusClass(ctx.Writer.Status())
+		requests.Inc(values...)
+		duration.Observe(time.Since(begin).Seconds(), values...)
+		requestSize.Observe(float64(max(ctx.Request.ContentLength, 0)), values...)
+		responseSize.Observe(float64(max(ctx.Writer.Size(), 0)), values...)
+	}
+}
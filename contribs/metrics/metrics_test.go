@@ -0,0 +1,37 @@
+package metrics
+
+import (
+	"bytes"
+	"github.com/gin-gonic/gin"
+	"github.com/ginx-contribs/ginx"
+	pkgmetrics "github.com/ginx-contribs/ginx/pkg/metrics"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	registry := pkgmetrics.NewRegistry()
+	server := ginx.New()
+	server.Engine().Use(Metrics(WithRegistry(registry), WithLabels("team")))
+
+	server.RouterGroup().MGET("/users/:id", ginx.M{Labels.V(map[string]string{"team": "account"})}, func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, "ok")
+	})
+	server.RouterGroup().MGET("/healthz", ginx.M{Skip.V(true)}, func(ctx *gin.Context) {})
+
+	for _, path := range []string{"/users/1", "/users/2", "/healthz", "/missing"} {
+		server.Engine().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	var buf bytes.Buffer
+	_, _ = registry.WriteTo(&buf)
+	output := buf.String()
+	assert.Contains(t, output, `ginx_http_requests_total{method="GET",route="/users/:id",status="2xx",team="account"} 2`)
+	assert.Contains(t, output, `ginx_http_requests_total{method="GET",route="unmatched",status="4xx",team=""} 1`)
+	assert.Contains(t, output, `ginx_http_requests_in_flight{method="GET",route="/users/:id",team="account"} 0`)
+	assert.Contains(t, output, `ginx_http_response_size_bytes_sum{method="GET",route="/users/:id",status="2xx",team="account"} 4`)
+	assert.NotContains(t, output, "/healthz")
+}
@@ -0,0 +1,168 @@
+package mtls
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/ginx-contribs/ginx"
+	"github.com/ginx-contribs/ginx/pkg/resp"
+	"github.com/ginx-contribs/ginx/pkg/resp/statuserr"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrNoPeerCert = errors.New("no verified client certificate")
+	ErrNotAllowed = errors.New("client is not allowed")
+)
+
+// Allow declares the clients allowed to access the route, each entry is one of the following forms:
+//
+//	spiffe://trust-domain/path    SPIFFE ID in URI SAN, suffix /* matches any path under it
+//	cn:<name>                     subject common name
+//	dns:<name>                    DNS SAN
+//	uri:<uri>                     URI SAN
+//	*                             any verified client
+//
+// It is declared in group metadata usually, and subgroups or routes could override it.
+var Allow = ginx.NewKey[[]string]("mtls.allow")
+
+const peerKey = "github.com/ginx-contribs/ginx/contribs/mtls.peer"
+
+type peerCtxKey struct{}
+
+// Peer is the identity extracted from the verified client certificate
+type Peer struct {
+	Subject    string
+	CommonName string
+	DNSNames   []string
+	URIs       []string
+	// the first URI SAN with spiffe scheme
+	SPIFFEID    string
+	Certificate *x509.Certificate
+}
+
+func newPeer(cert *x509.Certificate) *Peer {
+	peer := &Peer{
+		Subject:     cert.Subject.String(),
+		CommonName:  cert.Subject.CommonName,
+		DNSNames:    cert.DNSNames,
+		Certificate: cert,
+	}
+	for _, uri := range cert.URIs {
+		peer.URIs = append(peer.URIs, uri.String())
+		if uri.Scheme == "spiffe" && peer.SPIFFEID == "" {
+			peer.SPIFFEID = uri.String()
+		}
+	}
+	return peer
+}
+
+// Match reports whether peer matches the allow-list entry
+func (p *Peer) Match(entry string) bool {
+	switch {
+	case entry == "*":
+		return true
+	case strings.HasPrefix(entry, "spiffe://"):
+		if prefix, ok := strings.CutSuffix(entry, "/*"); ok {
+			return strings.HasPrefix(p.SPIFFEID, prefix+"/")
+		}
+		return p.SPIFFEID == entry
+	case strings.HasPrefix(entry, "cn:"):
+		return p.CommonName == entry[len("cn:"):]
+	case strings.HasPrefix(entry, "dns:"):
+		return slices.Contains(p.DNSNames, entry[len("dns:"):])
+	case strings.HasPrefix(entry, "uri:"):
+		return slices.Contains(p.URIs, entry[len("uri:"):])
+	}
+	return false
+}
+
+type Options struct {
+	// reject the requests without verified client certificate even if route does not declare Allow
+	RequirePeer  bool
+	ErrorHandler func(ctx *gin.Context, err error)
+}
+
+type Option func(options *Options)
+
+func WithRequirePeer(require bool) Option {
+	return func(options *Options) {
+		options.RequirePeer = require
+	}
+}
+
+func WithErrorHandler(handler func(ctx *gin.Context, err error)) Option {
+	return func(options *Options) {
+		options.ErrorHandler = handler
+	}
+}
+
+// MTLS returns a handler which extracts the peer identity from the verified client certificate into context,
+// and checks it against the Allow metadata of route. The client certificate should be verified by TLS server,
+// see ginx.TLSOptions.ClientCAs.
+func MTLS(opts ...Option) gin.HandlerFunc {
+	var options Options
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if options.ErrorHandler == nil {
+		options.ErrorHandler = func(ctx *gin.Context, err error) {
+			if errors.Is(err, ErrNoPeerCert) {
+				resp.Fail(ctx).Error(statuserr.UnAuthorized(err)).JSON()
+			} else {
+				resp.Fail(ctx).Error(statuserr.Forbidden(err)).JSON()
+			}
+			ctx.Abort()
+		}
+	}
+
+	return func(ctx *gin.Context) {
+		var peer *Peer
+		// only trust the verified chains, peer certificates may be not verified in request mode
+		if state := ctx.Request.TLS; state != nil && len(state.VerifiedChains) > 0 && len(state.VerifiedChains[0]) > 0 {
+			peer = newPeer(state.VerifiedChains[0][0])
+			withPeer(ctx, peer)
+		}
+
+		allowed, declared := Allow.FromCtx(ctx)
+		if peer == nil {
+			if declared || options.RequirePeer {
+				options.ErrorHandler(ctx, ErrNoPeerCert)
+				return
+			}
+			ctx.Next()
+			return
+		}
+
+		if declared && !slices.ContainsFunc(allowed, peer.Match) {
+			options.ErrorHandler(ctx, fmt.Errorf("%w: %s", ErrNotAllowed, peer.Subject))
+			return
+		}
+		ctx.Next()
+	}
+}
+
+func withPeer(ctx *gin.Context, peer *Peer) {
+	ctx.Set(peerKey, peer)
+	ctx.Request = ctx.Request.WithContext(context.WithValue(ctx.Request.Context(), peerCtxKey{}, peer))
+}
+
+// FromCtx returns the peer identity from context, both *gin.Context and the request context are accepted.
+func FromCtx(ctx context.Context) (*Peer, bool) {
+	if ginCtx, ok := ctx.(*gin.Context); ok {
+		if v, exists := ginCtx.Get(peerKey); exists {
+			peer, ok := v.(*Peer)
+			return peer, ok
+		}
+		if ginCtx.Request == nil {
+			return nil, false
+		}
+		ctx = ginCtx.Request.Context()
+	}
+	peer, ok := ctx.Value(peerCtxKey{}).(*Peer)
+	return peer, ok
+}
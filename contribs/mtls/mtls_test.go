@@ -0,0 +1,74 @@
+package mtls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"github.com/gin-gonic/gin"
+	"github.com/ginx-contribs/ginx"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func newCert(cn string, dnsNames []string, uris ...string) *x509.Certificate {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: cn}, DNSNames: dnsNames}
+	for _, uri := range uris {
+		u, _ := url.Parse(uri)
+		cert.URIs = append(cert.URIs, u)
+	}
+	return cert
+}
+
+func TestMatch(t *testing.T) {
+	peer := newPeer(newCert("billing", []string{"billing.internal"}, "spiffe://example.org/ns/prod/sa/billing"))
+	assert.Equal(t, "spiffe://example.org/ns/prod/sa/billing", peer.SPIFFEID)
+
+	assert.True(t, peer.Match("*"))
+	assert.True(t, peer.Match("spiffe://example.org/ns/prod/sa/billing"))
+	assert.True(t, peer.Match("spiffe://example.org/ns/prod/*"))
+	assert.False(t, peer.Match("spiffe://example.org/ns/dev/*"))
+	assert.False(t, peer.Match("spiffe://example.org/ns/prod/sa/bill"))
+	assert.True(t, peer.Match("cn:billing"))
+	assert.True(t, peer.Match("dns:billing.internal"))
+	assert.True(t, peer.Match("uri:spiffe://example.org/ns/prod/sa/billing"))
+	assert.False(t, peer.Match("billing"))
+}
+
+func TestMTLS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	server := ginx.New()
+	server.Engine().Use(MTLS())
+
+	var subject string
+	handler := func(ctx *gin.Context) {
+		if peer, ok := FromCtx(ctx.Request.Context()); ok {
+			subject = peer.CommonName
+		}
+	}
+	server.RouterGroup().GET("/open", handler)
+	internal := server.RouterGroup().MGroup("/internal", ginx.M{Allow.V([]string{"spiffe://example.org/ns/prod/*"})})
+	internal.GET("/orders", handler)
+	internal.MGET("/admin", ginx.M{Allow.V([]string{"cn:admin"})}, handler)
+
+	request := func(path string, cert *x509.Certificate) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if cert != nil {
+			req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+		}
+		rec := httptest.NewRecorder()
+		server.Engine().ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	billing := newCert("billing", nil, "spiffe://example.org/ns/prod/sa/billing")
+	assert.Equal(t, http.StatusOK, request("/open", nil))
+	assert.Equal(t, http.StatusUnauthorized, request("/internal/orders", nil))
+	assert.Equal(t, http.StatusOK, request("/internal/orders", billing))
+	assert.Equal(t, "billing", subject)
+	assert.Equal(t, http.StatusForbidden, request("/internal/orders", newCert("dev", nil, "spiffe://example.org/ns/dev/sa/billing")))
+	assert.Equal(t, http.StatusForbidden, request("/internal/admin", billing))
+	assert.Equal(t, http.StatusOK, request("/internal/admin", newCert("admin", nil)))
+}
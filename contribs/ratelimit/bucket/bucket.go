@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 	ginxratelmit "github.com/ginx-contribs/ginx/contribs/ratelimit"
 	"github.com/juju/ratelimit"
+	"sync"
 	"time"
 )
 
@@ -12,16 +13,23 @@ type Limiter struct {
 	bucket *ratelimit.Bucket
 	// max timeout to wait buckets becomes available
 	maxWait time.Duration
+
+	// protects the fields from updating at runtime
+	mu sync.RWMutex
 }
 
 func (b *Limiter) Allow(ctx *gin.Context) (func(), error) {
+	b.mu.RLock()
+	bucket, maxWait := b.bucket, b.maxWait
+	b.mu.RUnlock()
+
 	var take int64
-	if b.maxWait <= 0 {
-		if b.bucket.TakeAvailable(take) <= 0 {
+	if maxWait <= 0 {
+		if bucket.TakeAvailable(take) <= 0 {
 			return nil, ginxratelmit.ErrRateLimitExceed
 		}
 	} else {
-		if !b.bucket.WaitMaxDuration(take, b.maxWait) {
+		if !bucket.WaitMaxDuration(take, maxWait) {
 			return nil, ginxratelmit.ErrRateLimitExceed
 		}
 	}
@@ -54,3 +62,30 @@ func NewLimiter(opts ...Option) *Limiter {
 
 	return &limiter
 }
+
+// Config is the declarative options of Limiter, it could be loaded from config files by pkg/config.
+type Config struct {
+	// interval to add a token into bucket
+	FillInterval time.Duration `mapstructure:"fillInterval"`
+	// max tokens of bucket
+	Capacity int64         `mapstructure:"capacity"`
+	MaxWait  time.Duration `mapstructure:"maxWait"`
+}
+
+// Update applies the new config at runtime, the bucket is replaced only if both FillInterval and Capacity are set
+func (b *Limiter) Update(config Config) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, opt := range config.Options() {
+		opt(b)
+	}
+}
+
+// Options converts config to options of NewLimiter
+func (c Config) Options() []Option {
+	opts := []Option{WithMaxWait(c.MaxWait)}
+	if c.FillInterval > 0 && c.Capacity > 0 {
+		opts = append(opts, WithBucket(ratelimit.NewBucket(c.FillInterval, c.Capacity)))
+	}
+	return opts
+}
@@ -8,7 +8,7 @@ import (
 )
 
 func Cache() *CacheCounter {
-	c := cache.New(cache.NoExpiration, cache.NoExpiration)
+	c := cache.New(cache.NoExpiration, time.Minute)
 	return &CacheCounter{cache: c}
 }
 
@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/ginx-contribs/ginx/contribs/ratelimit"
 	"golang.org/x/net/context"
+	"sync"
 	"time"
 )
 
@@ -18,15 +19,22 @@ type Limiter struct {
 	Window  time.Duration
 	KeyFn   func(ctx *gin.Context) string
 	Counter Counter
+
+	// protects the fields from updating at runtime
+	mu sync.RWMutex
 }
 
 func (c *Limiter) Allow(ctx *gin.Context) (func(), error) {
-	key := c.KeyFn(ctx)
-	count, err := c.Counter.Count(ctx, key, c.Limit, c.Window)
+	c.mu.RLock()
+	keyFn, limit, window := c.KeyFn, c.Limit, c.Window
+	c.mu.RUnlock()
+
+	key := keyFn(ctx)
+	count, err := c.Counter.Count(ctx, key, limit, window)
 	if err != nil {
 		return nil, err
 	}
-	if count >= c.Limit {
+	if count >= limit {
 		return nil, ratelimit.ErrRateLimitExceed
 	}
 	return func() {}, nil
@@ -94,3 +102,38 @@ func NewLimiter(options ...Option) *Limiter {
 
 	return &limiter
 }
+
+// Config is the declarative options of Limiter, it could be loaded from config files by pkg/config.
+type Config struct {
+	Limit  int           `mapstructure:"limit"`
+	Window time.Duration `mapstructure:"window"`
+	// key of counting, url or ip, default is url
+	Key string `mapstructure:"key"`
+}
+
+// Update applies the new config at runtime, zero values are ignored
+func (c *Limiter) Update(config Config) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, opt := range config.Options() {
+		opt(c)
+	}
+}
+
+// Options converts config to options of NewLimiter, zero values are ignored
+func (c Config) Options() []Option {
+	var opts []Option
+	if c.Limit > 0 {
+		opts = append(opts, WithLimit(c.Limit))
+	}
+	if c.Window > 0 {
+		opts = append(opts, WithWindow(c.Window))
+	}
+	switch c.Key {
+	case "url":
+		opts = append(opts, WithKeyFn(UrlKey()))
+	case "ip":
+		opts = append(opts, WithKeyFn(ClientIpKey()))
+	}
+	return opts
+}
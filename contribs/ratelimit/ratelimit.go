@@ -4,12 +4,15 @@ import (
 	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/ginx-contribs/ginx/constant/status"
+	"github.com/ginx-contribs/ginx/pkg/metrics"
 	"github.com/ginx-contribs/ginx/pkg/resp"
 )
 
 type Options struct {
 	Limiter      Limiter
 	ErrorHandler func(ctx *gin.Context, err error)
+	// counts the allowed and limited requests if not nil
+	Metrics *metrics.Registry
 }
 
 type Option func(options *Options)
@@ -26,6 +29,14 @@ func WithErrorHandler(errorHandler func(ctx *gin.Context, err error)) Option {
 	}
 }
 
+// WithMetrics counts the requests into ginx_ratelimit_requests_total labelled by route and result,
+// the result is one of allowed, limited and error.
+func WithMetrics(registry *metrics.Registry) Option {
+	return func(options *Options) {
+		options.Metrics = registry
+	}
+}
+
 // RateLimit returns a new limiter handler with options
 func RateLimit(opts ...Option) gin.HandlerFunc {
 	var options Options
@@ -50,9 +61,24 @@ func RateLimit(opts ...Option) gin.HandlerFunc {
 		}
 	}
 
+	observe := func(ctx *gin.Context, err error) {}
+	if options.Metrics != nil {
+		requests := options.Metrics.Counter("ginx_ratelimit_requests_total", "Total number of requests checked by rate limiter.", "route", "result")
+		observe = func(ctx *gin.Context, err error) {
+			result := "allowed"
+			if errors.Is(err, ErrRateLimitExceed) {
+				result = "limited"
+			} else if err != nil {
+				result = "error"
+			}
+			requests.Inc(metrics.Route(ctx), result)
+		}
+	}
+
 	return func(ctx *gin.Context) {
 		// try to allow request
 		done, err := options.Limiter.Allow(ctx)
+		observe(ctx, err)
 		// allow
 		if err == nil {
 			ctx.Next()
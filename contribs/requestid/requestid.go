@@ -3,12 +3,15 @@ package requestid
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/ginx-contribs/ginx/constant/headers"
+	"github.com/ginx-contribs/ginx/pkg/trace"
 	"github.com/google/uuid"
 )
 
 type Options struct {
 	Header    string
 	Generator func(ctx *gin.Context) string
+	// use trace id as request id if the request is traced, see contribs/tracing
+	TraceId bool
 }
 
 type Option func(options *Options)
@@ -25,6 +28,14 @@ func WithGenerator(gen func(ctx *gin.Context) string) Option {
 	}
 }
 
+// WithTraceId uses the trace id of the span in request context as request id, it takes precedence over
+// the generator, so the handler should be placed after the tracing handler.
+func WithTraceId() Option {
+	return func(options *Options) {
+		options.TraceId = true
+	}
+}
+
 // RequestId returns the request-id gin handler
 func RequestId(opts ...Option) gin.HandlerFunc {
 	var options Options
@@ -45,6 +56,11 @@ func RequestId(opts ...Option) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		// if client has already carried with request-id
 		id := ctx.Request.Header.Get(options.Header)
+		if id == "" && options.TraceId {
+			if sc, ok := trace.SpanContextFromContext(ctx.Request.Context()); ok {
+				id = sc.TraceID.String()
+			}
+		}
 		if id == "" { // generate a new one
 			id = options.Generator(ctx)
 		}
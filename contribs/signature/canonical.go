@@ -0,0 +1,111 @@
+package signature
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// signature headers
+const (
+	HeaderKeyId         = "X-Key-Id"
+	HeaderTimestamp     = "X-Timestamp"
+	HeaderNonce         = "X-Nonce"
+	HeaderSignedHeaders = "X-Signed-Headers"
+	HeaderSignature     = "X-Signature"
+)
+
+// Canonicalize builds the string to sign, both server and client use it, so they always agree on the same result.
+// It consists of the following lines joined by '\n':
+//
+//	METHOD
+//	escaped path
+//	query sorted by key and value
+//	lowercase header name:trimmed value, one line for each signed header in the order of signedHeaders
+//	timestamp
+//	nonce
+//	hex encoded sha256 digest of body
+func Canonicalize(req *http.Request, body []byte, signedHeaders []string, timestamp, nonce string) string {
+	var buf strings.Builder
+	buf.WriteString(strings.ToUpper(req.Method))
+	buf.WriteByte('\n')
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	buf.WriteString(path)
+	buf.WriteByte('\n')
+
+	buf.WriteString(canonicalQuery(req.URL.Query()))
+	buf.WriteByte('\n')
+
+	for _, name := range signedHeaders {
+		name = strings.ToLower(strings.TrimSpace(name))
+		buf.WriteString(name)
+		buf.WriteByte(':')
+		buf.WriteString(headerValue(req, name))
+		buf.WriteByte('\n')
+	}
+
+	buf.WriteString(timestamp)
+	buf.WriteByte('\n')
+	buf.WriteString(nonce)
+	buf.WriteByte('\n')
+
+	digest := sha256.Sum256(body)
+	buf.WriteString(hex.EncodeToString(digest[:]))
+	return buf.String()
+}
+
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var pairs []string
+	for _, key := range keys {
+		values := slices.Clone(query[key])
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs, url.QueryEscape(key)+"="+url.QueryEscape(value))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+func headerValue(req *http.Request, name string) string {
+	// host is removed from header by net/http
+	if name == "host" {
+		if req.Host != "" {
+			return req.Host
+		}
+		return req.URL.Host
+	}
+	values := slices.Clone(req.Header.Values(name))
+	for i, value := range values {
+		values[i] = strings.TrimSpace(value)
+	}
+	return strings.Join(values, ",")
+}
+
+// Sign returns hex encoded HMAC-SHA256 of the canonical string
+func Sign(secret []byte, canonical string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(canonical))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func parseSignedHeaders(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(strings.ToLower(value), ";")
+}
@@ -0,0 +1,105 @@
+package signature
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Signer signs the outgoing requests with the same canonicalization as Verify, it implements http.RoundTripper.
+type Signer struct {
+	KeyId  string
+	Secret []byte
+	// headers to be signed, host is always recommended
+	Headers []string
+	// underlying transport, default is http.DefaultTransport
+	Transport http.RoundTripper
+
+	now func() time.Time
+}
+
+// NewSigner returns a signer which signs the given headers
+func NewSigner(keyId string, secret []byte, headers ...string) *Signer {
+	return &Signer{KeyId: keyId, Secret: secret, Headers: headers}
+}
+
+// Client returns a http client which signs every request
+func (s *Signer) Client() *http.Client {
+	return &http.Client{Transport: s}
+}
+
+// RoundTrip signs a copy of request, then sends it by the underlying transport
+func (s *Signer) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	if err := s.Sign(req); err != nil {
+		return nil, err
+	}
+	transport := s.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return transport.RoundTrip(req)
+}
+
+// Sign sets the signature headers into request, the body will be read and replaced.
+func (s *Signer) Sign(req *http.Request) error {
+	body, err := s.body(req)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now
+	if s.now != nil {
+		now = s.now
+	}
+	timestamp := strconv.FormatInt(now().Unix(), 10)
+	nonce, err := newNonce()
+	if err != nil {
+		return err
+	}
+
+	signedHeaders := make([]string, len(s.Headers))
+	for i, header := range s.Headers {
+		signedHeaders[i] = strings.ToLower(header)
+	}
+
+	req.Header.Set(HeaderKeyId, s.KeyId)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderNonce, nonce)
+	if len(signedHeaders) > 0 {
+		req.Header.Set(HeaderSignedHeaders, strings.Join(signedHeaders, ";"))
+	}
+	req.Header.Set(HeaderSignature, Sign(s.Secret, Canonicalize(req, body, signedHeaders, timestamp, nonce)))
+	return nil
+}
+
+func (s *Signer) body(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	return body, nil
+}
+
+func newNonce() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,253 @@
+package signature
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/ginx-contribs/ginx/contribs/ratelimit/counter"
+	"github.com/ginx-contribs/ginx/pkg/resp"
+	"github.com/ginx-contribs/ginx/pkg/resp/statuserr"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrMissingKey       = errors.New("missing api key")
+	ErrMissingSignature = errors.New("missing signature")
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrTimestampSkew    = errors.New("timestamp is out of allowed range")
+	ErrReplayed         = errors.New("nonce has been used")
+	ErrUnsignedHeader   = errors.New("required header is not signed")
+	ErrBodyTooLarge     = errors.New("request body is too large")
+)
+
+type Options struct {
+	// where to find the credential
+	Store KeyStore
+
+	// header to read api key from, default is X-Api-Key
+	Header string
+	// query parameter to read api key from, empty means api key is only read from header
+	Query string
+
+	// headers which must be signed by client, they are lowercase
+	SignedHeaders []string
+	// max difference between request timestamp and server time, default is 5 minutes
+	MaxSkew time.Duration
+	// counter used to detect nonce replay, default is counter.Cache
+	NonceCounter counter.Counter
+	// max body size to be read for digest, default is 10MB
+	MaxBodySize int64
+
+	ErrorHandler func(ctx *gin.Context, err error)
+
+	now func() time.Time
+}
+
+type Option func(options *Options)
+
+func WithStore(store KeyStore) Option {
+	return func(options *Options) {
+		options.Store = store
+	}
+}
+
+func WithHeader(header string) Option {
+	return func(options *Options) {
+		options.Header = header
+	}
+}
+
+func WithQuery(query string) Option {
+	return func(options *Options) {
+		options.Query = query
+	}
+}
+
+func WithSignedHeaders(headers ...string) Option {
+	return func(options *Options) {
+		options.SignedHeaders = headers
+	}
+}
+
+func WithMaxSkew(skew time.Duration) Option {
+	return func(options *Options) {
+		options.MaxSkew = skew
+	}
+}
+
+func WithNonceCounter(counter counter.Counter) Option {
+	return func(options *Options) {
+		options.NonceCounter = counter
+	}
+}
+
+func WithMaxBodySize(size int64) Option {
+	return func(options *Options) {
+		options.MaxBodySize = size
+	}
+}
+
+func WithErrorHandler(handler func(ctx *gin.Context, err error)) Option {
+	return func(options *Options) {
+		options.ErrorHandler = handler
+	}
+}
+
+func newOptions(opts []Option) Options {
+	var options Options
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if options.Store == nil {
+		panic("signature: KeyStore is required")
+	}
+
+	if options.Header == "" {
+		options.Header = "X-Api-Key"
+	}
+
+	if options.MaxSkew <= 0 {
+		options.MaxSkew = 5 * time.Minute
+	}
+
+	if options.NonceCounter == nil {
+		options.NonceCounter = counter.Cache()
+	}
+
+	if options.MaxBodySize <= 0 {
+		options.MaxBodySize = 10 << 20
+	}
+
+	options.SignedHeaders = slices.Clone(options.SignedHeaders)
+	for i, header := range options.SignedHeaders {
+		options.SignedHeaders[i] = strings.ToLower(header)
+	}
+
+	if options.ErrorHandler == nil {
+		options.ErrorHandler = func(ctx *gin.Context, err error) {
+			resp.Fail(ctx).Error(statuserr.UnAuthorized(err)).JSON()
+			ctx.Abort()
+		}
+	}
+
+	if options.now == nil {
+		options.now = time.Now
+	}
+	return options
+}
+
+// APIKey returns a handler which authenticates request by api key carried in header or query,
+// the key is looked up from KeyStore, use FromCtx to get the credential.
+func APIKey(opts ...Option) gin.HandlerFunc {
+	options := newOptions(opts)
+
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader(options.Header)
+		if key == "" && options.Query != "" {
+			key = ctx.Query(options.Query)
+		}
+		if key == "" {
+			options.ErrorHandler(ctx, ErrMissingKey)
+			return
+		}
+
+		credential, err := options.Store.Lookup(ctx, key)
+		if err != nil {
+			options.ErrorHandler(ctx, err)
+			return
+		}
+		setCredential(ctx, credential)
+		ctx.Next()
+	}
+}
+
+// Verify returns a handler which verifies the HMAC signature of request, the request should carry
+// key id, timestamp, nonce, signed headers and signature in headers, see Signer for client side.
+func Verify(opts ...Option) gin.HandlerFunc {
+	options := newOptions(opts)
+
+	return func(ctx *gin.Context) {
+		credential, err := verify(ctx, &options)
+		if err != nil {
+			options.ErrorHandler(ctx, err)
+			return
+		}
+		setCredential(ctx, credential)
+		ctx.Next()
+	}
+}
+
+func verify(ctx *gin.Context, options *Options) (*Credential, error) {
+	keyId := ctx.GetHeader(HeaderKeyId)
+	signature := ctx.GetHeader(HeaderSignature)
+	nonce := ctx.GetHeader(HeaderNonce)
+	timestamp := ctx.GetHeader(HeaderTimestamp)
+	if keyId == "" || signature == "" || nonce == "" || timestamp == "" {
+		return nil, ErrMissingSignature
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid timestamp", ErrInvalidSignature)
+	}
+	if skew := options.now().Sub(time.Unix(unix, 0)).Abs(); skew > options.MaxSkew {
+		return nil, ErrTimestampSkew
+	}
+
+	signedHeaders := parseSignedHeaders(ctx.GetHeader(HeaderSignedHeaders))
+	for _, header := range options.SignedHeaders {
+		if !slices.Contains(signedHeaders, header) {
+			return nil, fmt.Errorf("%w: %s", ErrUnsignedHeader, header)
+		}
+	}
+
+	credential, err := options.Store.Lookup(ctx, keyId)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := readBody(ctx, options.MaxBodySize)
+	if err != nil {
+		return nil, err
+	}
+
+	expected := Sign(credential.Secret, Canonicalize(ctx.Request, body, signedHeaders, timestamp, nonce))
+	if !hmac.Equal([]byte(expected), []byte(strings.ToLower(signature))) {
+		return nil, ErrInvalidSignature
+	}
+
+	// check nonce after signature verified, so that nonce can not be consumed by forged requests.
+	// the nonce only needs to be remembered while the timestamp is acceptable.
+	count, err := options.NonceCounter.Count(ctx, "signature:nonce:"+keyId+":"+nonce, 2, 2*options.MaxSkew)
+	if err != nil {
+		return nil, err
+	}
+	if count > 1 {
+		return nil, ErrReplayed
+	}
+	return credential, nil
+}
+
+// readBody reads the whole body and puts it back for the following handlers
+func readBody(ctx *gin.Context, limit int64) ([]byte, error) {
+	if ctx.Request.Body == nil || ctx.Request.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(io.LimitReader(ctx.Request.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > limit {
+		return nil, ErrBodyTooLarge
+	}
+	ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
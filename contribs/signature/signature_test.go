@@ -0,0 +1,100 @@
+package signature
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testServer(t *testing.T, handlers ...gin.HandlerFunc) *httptest.Server {
+	engine := gin.New()
+	engine.Use(handlers...)
+	engine.POST("/echo", func(ctx *gin.Context) {
+		credential, ok := FromCtx(ctx)
+		require.True(t, ok)
+		body, err := io.ReadAll(ctx.Request.Body)
+		require.NoError(t, err)
+		ctx.String(http.StatusOK, credential.Subject+":"+string(body))
+	})
+	server := httptest.NewServer(engine)
+	t.Cleanup(server.Close)
+	return server
+}
+
+var store = StaticStore{"client": {Secret: []byte("secret"), Subject: "jack"}}
+
+func TestCanonicalize(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "http://example.com/a%20b?b=2&a=3&a=1", nil)
+	req.Header.Add("Content-Type", " text/plain ")
+	got := Canonicalize(req, []byte("body"), []string{"Host", "content-type"}, "1", "n")
+	want := "POST\n/a%20b\na=1&a=3&b=2\nhost:example.com\ncontent-type:text/plain\n1\nn\n" +
+		"230d8358dc8e8890b4c58deeb62912ee2f20357ae92a5cc861b98e68fe31acb5"
+	assert.Equal(t, want, got)
+}
+
+func TestVerify(t *testing.T) {
+	server := testServer(t, Verify(WithStore(store), WithSignedHeaders("Host"), WithMaxSkew(time.Minute)))
+	signer := NewSigner("client", []byte("secret"), "host")
+
+	res, err := signer.Client().Post(server.URL+"/echo?x=1", "text/plain", strings.NewReader("hello"))
+	require.NoError(t, err)
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "jack:hello", string(body))
+
+	send := func(mutate func(req *http.Request)) int {
+		req, err := http.NewRequest(http.MethodPost, server.URL+"/echo", strings.NewReader("hello"))
+		require.NoError(t, err)
+		require.NoError(t, signer.Sign(req))
+		mutate(req)
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		res.Body.Close()
+		return res.StatusCode
+	}
+
+	// tampered body
+	assert.Equal(t, http.StatusUnauthorized, send(func(req *http.Request) {
+		req.Body, req.GetBody = io.NopCloser(strings.NewReader("HELLO")), nil
+	}))
+	// required header is not signed
+	assert.Equal(t, http.StatusUnauthorized, send(func(req *http.Request) {
+		req.Header.Del(HeaderSignedHeaders)
+	}))
+
+	// replay
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/echo", nil)
+	require.NoError(t, err)
+	require.NoError(t, signer.Sign(req))
+	for _, want := range []int{http.StatusOK, http.StatusUnauthorized} {
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		res.Body.Close()
+		assert.Equal(t, want, res.StatusCode)
+	}
+
+	// timestamp skew
+	signer.now = func() time.Time { return time.Now().Add(-2 * time.Minute) }
+	assert.Equal(t, http.StatusUnauthorized, send(func(req *http.Request) {}))
+}
+
+func TestAPIKey(t *testing.T) {
+	server := testServer(t, APIKey(WithStore(store), WithQuery("api_key")))
+	for url, want := range map[string]int{
+		"/echo":                http.StatusUnauthorized,
+		"/echo?api_key=client": http.StatusOK,
+		"/echo?api_key=other":  http.StatusUnauthorized,
+	} {
+		res, err := http.Post(server.URL+url, "text/plain", nil)
+		require.NoError(t, err)
+		res.Body.Close()
+		assert.Equal(t, want, res.StatusCode, url)
+	}
+}
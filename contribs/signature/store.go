@@ -0,0 +1,61 @@
+package signature
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+)
+
+const credentialKey = "github.com/ginx-contribs/ginx/contribs/signature.credential"
+
+var ErrKeyNotFound = errors.New("key not found")
+
+// Credential is the API key or the signing key of a client
+type Credential struct {
+	// key id, for API key authentication it is the API key itself
+	ID string
+	// secret used to sign the request, it is not used in API key authentication
+	Secret []byte
+	// who owns the key
+	Subject string
+}
+
+// KeyStore finds credential by key id, it should return ErrKeyNotFound if the key does not exist.
+type KeyStore interface {
+	Lookup(ctx context.Context, id string) (*Credential, error)
+}
+
+// KeyStoreFunc is an adapter to use ordinary function as KeyStore
+type KeyStoreFunc func(ctx context.Context, id string) (*Credential, error)
+
+func (f KeyStoreFunc) Lookup(ctx context.Context, id string) (*Credential, error) {
+	return f(ctx, id)
+}
+
+// StaticStore is a KeyStore in memory, key is the key id
+type StaticStore map[string]Credential
+
+func (s StaticStore) Lookup(_ context.Context, id string) (*Credential, error) {
+	credential, ok := s[id]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	if credential.ID == "" {
+		credential.ID = id
+	}
+	return &credential, nil
+}
+
+func setCredential(ctx *gin.Context, credential *Credential) {
+	ctx.Set(credentialKey, credential)
+}
+
+// FromCtx returns the authenticated credential stored in context
+func FromCtx(ctx *gin.Context) (*Credential, bool) {
+	v, exists := ctx.Get(credentialKey)
+	if !exists {
+		return nil, false
+	}
+	credential, ok := v.(*Credential)
+	return credential, ok
+}
@@ -0,0 +1,130 @@
+package tracing
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/ginx-contribs/ginx"
+	"github.com/ginx-contribs/ginx/pkg/trace"
+	"math"
+	"net/http"
+	"reflect"
+	"slices"
+)
+
+type Options struct {
+	// tracer to start spans, spans are not exported if nil
+	Tracer *trace.Tracer
+	// record route metadata as span attributes with prefix "ginx.meta.", it is disabled by default,
+	// only the values of string, bool, number and []string are recorded.
+	RecordMeta bool
+	// keys of metadata to record, all the keys are recorded if empty
+	MetaKeys []string
+}
+
+type Option func(options *Options)
+
+func WithTracer(tracer *trace.Tracer) Option {
+	return func(options *Options) {
+		options.Tracer = tracer
+	}
+}
+
+func WithRecordMeta(record bool) Option {
+	return func(options *Options) {
+		options.RecordMeta = record
+	}
+}
+
+// WithMetaKeys records only the metadata of keys, it enables RecordMeta
+func WithMetaKeys(keys ...string) Option {
+	return func(options *Options) {
+		options.RecordMeta = true
+		options.MetaKeys = append(options.MetaKeys, keys...)
+	}
+}
+
+// Tracing returns a handler which starts a server span for each request, the parent is extracted from
+// W3C traceparent and tracestate headers. The span is carried by the request context, use trace.SpanFromContext
+// to get it, and use trace.Transport or trace.Inject to propagate it to downstream services.
+func Tracing(opts ...Option) gin.HandlerFunc {
+	var options Options
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if options.Tracer == nil {
+		options.Tracer = trace.NewTracer()
+	}
+
+	return func(ctx *gin.Context) {
+		req := ctx.Request
+		parent, _ := trace.Extract(req.Header)
+
+		spanCtx, span := options.Tracer.Start(req.Context(), SpanName(ctx),
+			trace.WithKind(trace.SpanKindServer),
+			trace.WithRemoteParent(parent),
+			trace.WithAttributes(map[string]any{
+				"http.request.method": req.Method,
+				"http.route":          ctx.FullPath(),
+				"url.path":            req.URL.Path,
+				"client.address":      ctx.ClientIP(),
+				"user_agent.original": req.UserAgent(),
+			}),
+		)
+		defer span.End()
+		ctx.Request = req.WithContext(spanCtx)
+
+		if options.RecordMeta && span.IsRecording() {
+			ginx.MetaFromCtx(ctx).Range(func(key string, val any) bool {
+				if len(options.MetaKeys) > 0 && !slices.Contains(options.MetaKeys, key) {
+					return true
+				}
+				if attr, ok := attributeValue(val); ok {
+					span.SetAttribute("ginx.meta."+key, attr)
+				}
+				return true
+			})
+		}
+
+		ctx.Next()
+
+		status := ctx.Writer.Status()
+		span.SetAttribute("http.response.status_code", status)
+		// errors are only recorded as events, client errors are not errors of server span
+		for _, err := range ctx.Errors {
+			span.RecordError(err.Err)
+		}
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(trace.StatusError, fmt.Sprintf("%d %s", status, http.StatusText(status)))
+		}
+	}
+}
+
+// SpanName returns the name of server span, which is the method and route template, like "GET /users/:id".
+func SpanName(ctx *gin.Context) string {
+	if route := ctx.FullPath(); route != "" {
+		return ctx.Request.Method + " " + route
+	}
+	return ctx.Request.Method
+}
+
+// attributeValue returns the value which could be a span attribute, only scalar values and []string are accepted,
+// integers are converted to int64 and floats are converted to float64.
+func attributeValue(val any) (any, bool) {
+	switch v := val.(type) {
+	case string, bool, int, int64, float64, []string:
+		return v, true
+	}
+	rv := reflect.ValueOf(val)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if u := rv.Uint(); u <= math.MaxInt64 {
+			return int64(u), true
+		}
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return nil, false
+}
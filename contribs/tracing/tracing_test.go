@@ -0,0 +1,100 @@
+package tracing
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/ginx-contribs/ginx"
+	"github.com/ginx-contribs/ginx/constant/headers"
+	"github.com/ginx-contribs/ginx/contribs/requestid"
+	"github.com/ginx-contribs/ginx/pkg/trace"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTracing(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	exporter := trace.NewInMemoryExporter()
+	server := ginx.New()
+	server.Engine().Use(Tracing(WithRecordMeta(true), WithTracer(trace.NewTracer(trace.WithExporter(exporter), trace.WithSyncExport()))), requestid.RequestId(requestid.WithTraceId()))
+
+	var traceId string
+	server.RouterGroup().MGET("/users/:id", ginx.M{{Key: "role", Val: "admin"}}, func(ctx *gin.Context) {
+		if sc, ok := trace.SpanContextFromContext(ctx.Request.Context()); ok {
+			traceId = sc.TraceID.String()
+		}
+	})
+	server.RouterGroup().GET("/fail", func(ctx *gin.Context) {
+		_ = ctx.Error(errors.New("boom"))
+		ctx.Status(http.StatusInternalServerError)
+	})
+	server.RouterGroup().GET("/invalid", func(ctx *gin.Context) {
+		_ = ctx.Error(errors.New("invalid"))
+		ctx.Status(http.StatusBadRequest)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Set(headers.Traceparent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	req.Header.Set(headers.Tracestate, "vendor=1")
+	rec := httptest.NewRecorder()
+	server.Engine().ServeHTTP(rec, req)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", traceId)
+	assert.Equal(t, traceId, rec.Header().Get(headers.XRequestId))
+
+	server.Engine().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fail", nil))
+	server.Engine().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/invalid", nil))
+
+	spans := exporter.Spans()
+	require.Len(t, spans, 3)
+	assert.Equal(t, "GET /users/:id", spans[0].Name)
+	assert.Equal(t, trace.SpanKindServer, spans[0].Kind)
+	assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent.String())
+	assert.Equal(t, "vendor=1", spans[0].SpanContext.TraceState)
+	assert.Equal(t, "admin", spans[0].Attributes["ginx.meta.role"])
+	assert.Equal(t, http.StatusOK, spans[0].Attributes["http.response.status_code"])
+	assert.Equal(t, trace.StatusUnset, spans[0].Status)
+
+	assert.Equal(t, trace.StatusError, spans[1].Status)
+	require.Len(t, spans[1].Events, 1)
+	assert.Equal(t, "boom", spans[1].Events[0].Attributes["exception.message"])
+
+	// client error is recorded but does not mark the span as error
+	assert.Equal(t, trace.StatusUnset, spans[2].Status)
+	require.Len(t, spans[2].Events, 1)
+	assert.Equal(t, "invalid", spans[2].Events[0].Attributes["exception.message"])
+}
+
+func TestRecordMeta(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	record := func(opts ...Option) map[string]any {
+		exporter := trace.NewInMemoryExporter()
+		server := ginx.New()
+		opts = append(opts, WithTracer(trace.NewTracer(trace.WithExporter(exporter), trace.WithSyncExport())))
+		server.Engine().Use(Tracing(opts...))
+		server.RouterGroup().MGET("/users", ginx.M{
+			{Key: "role", Val: "admin"},
+			{Key: "level", Val: int32(3)},
+			{Key: "scopes", Val: []string{"read"}},
+			{Key: "secret", Val: struct{ Token string }{Token: "token"}},
+		}, func(ctx *gin.Context) {})
+		server.Engine().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users", nil))
+
+		attrs := make(map[string]any)
+		for k, v := range exporter.Spans()[0].Attributes {
+			if strings.HasPrefix(k, "ginx.meta.") {
+				attrs[strings.TrimPrefix(k, "ginx.meta.")] = v
+			}
+		}
+		return attrs
+	}
+
+	// disabled by default
+	assert.Empty(t, record())
+	// only scalar values and []string are recorded
+	assert.Equal(t, map[string]any{"role": "admin", "level": int64(3), "scopes": []string{"read"}}, record(WithRecordMeta(true)))
+	assert.Equal(t, map[string]any{"role": "admin"}, record(WithMetaKeys("role", "secret")))
+}
@@ -8,15 +8,20 @@ import (
 	"log/slog"
 )
 
+var (
+	roleKey  = ginx.NewKey[string]("role")
+	limitKey = ginx.NewKey[int]("limit")
+)
+
 func main() {
 	server := ginx.Default()
 	root := server.RouterGroup()
-	root.MGET("login", ginx.M{{"role", "guest"}, {"limit", 5}})
+	root.MGET("login", ginx.M{roleKey.V("guest"), limitKey.V(5)})
 	user := root.MGroup("user", nil)
-	user.MGET("info", ginx.M{{"role", "user"}}, func(ctx *gin.Context) {
+	user.MGET("info", ginx.M{roleKey.V("user")}, func(ctx *gin.Context) {
 		// get metadata from context
-		metaData := ginx.MetaFromCtx(ctx)
-		slog.Info(metaData.ShouldGet("role").String())
+		role, _ := roleKey.FromCtx(ctx)
+		slog.Info(role)
 	})
 
 	// walk root router
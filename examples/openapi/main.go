@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"github.com/ginx-contribs/ginx"
+	"github.com/ginx-contribs/ginx/pkg/openapi"
+	"log"
+	"net/http"
+)
+
+type LoginReq struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+type Token struct {
+	Token string `json:"token"`
+}
+
+func main() {
+	server := ginx.Default()
+	root := server.RouterGroup()
+	auth := root.MGroup("auth", ginx.M{ginx.MetaTags.V([]string{"auth"})})
+	auth.HandleTyped(http.MethodPost, "login", ginx.M{
+		ginx.MetaSummary.V("login with username and password"),
+	}, ginx.Typed(func(ctx context.Context, req LoginReq) (Token, error) {
+		// request and response types are recorded into metadata by ginx.Typed
+		return Token{Token: "token"}, nil
+	}))
+
+	// serve the document at /openapi.json
+	generator := openapi.New(openapi.Info{Title: "example", Version: "v1"})
+	generator.Serve(server, "/openapi.json")
+
+	// export the document into file
+	if err := generator.WriteFile(server, "openapi.yaml"); err != nil {
+		log.Fatal(err)
+	}
+
+	err := server.Spin()
+	if err != nil {
+		log.Fatal(err)
+	}
+}
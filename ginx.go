@@ -7,12 +7,19 @@ import (
 	"github.com/dstgo/size"
 	"github.com/gin-gonic/gin"
 	"github.com/ginx-contribs/ginx/middleware"
+	"github.com/ginx-contribs/ginx/pkg/config"
+	"github.com/ginx-contribs/ginx/pkg/health"
 	cmap "github.com/orcaman/concurrent-map/v2"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 	"io"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 )
@@ -51,6 +58,13 @@ func New(options ...Option) *Server {
 		option(server)
 	}
 
+	// keep a copy before applying defaults, it is used to find out changes when reloading
+	server.configured = server.options
+	if server.options.TLS != nil {
+		tlsOptions := *server.options.TLS
+		server.configured.TLS = &tlsOptions
+	}
+
 	if server.ctx == nil {
 		server.ctx = context.Background()
 	}
@@ -64,10 +78,18 @@ func New(options ...Option) *Server {
 		server.stopSignals = []os.Signal{syscall.SIGKILL, syscall.SIGTERM, syscall.SIGINT}
 	}
 
-	if server.options.Address == "" {
+	if server.options.Address == "" && len(server.options.Listeners) == 0 && len(server.listeners) == 0 {
 		server.options.Address = ":8080"
 	}
 
+	if server.restartTimeout == 0 {
+		server.restartTimeout = time.Second * 30
+	}
+
+	if server.options.TLS != nil && server.options.TLS.ExpiryWarning == 0 {
+		server.options.TLS.ExpiryWarning = time.Hour * 24 * 30
+	}
+
 	if server.options.MaxShutdownTimeout == 0 {
 		server.options.MaxShutdownTimeout = time.Second * 5
 	}
@@ -109,8 +131,54 @@ type Server struct {
 	// truly running server
 	httpserver *http.Server
 
+	// listeners supplied by caller
+	listeners []net.Listener
+	// listeners the server is actually serving on
+	bound []namedListener
+	// whether http2 has been configured into http server
+	http2Configured bool
+	// listeners passed by parent process or systemd, they are loaded once and taken by admin and main server
+	inherited       []namedListener
+	inheritedLoaded bool
+
+	// tls certificates source, it is loaded before listening
+	certs *CertStore
+	// stop watching certificates
+	stopTLS context.CancelFunc
+	// certificate expiry hook
+	certExpiry CertExpiryFn
+
+	// signal to trigger graceful restart, nil means disabled
+	restartSignal os.Signal
+	// max wait time for new process to be ready
+	restartTimeout time.Duration
+
+	// config source to reload
+	configOpts []config.Option
+	// signal to trigger reloading, nil means disabled
+	reloadSignal os.Signal
+	// options supplied by caller, defaults are not applied
+	configured Options
+	reloadMu   sync.Mutex
+	snapshot   *config.Snapshot
+	watchers   []watcher
+
+	// admin server, nil if admin address is not configured
+	admin         *http.Server
+	adminListener net.Listener
+
+	state atomic.Int32
+	hooks hookRecorder
+	// readiness of health registry follows the server state
+	health *health.Registry
+	// server level metrics, such as connections and hook durations
+	metrics *serverMetrics
+
 	engine *gin.Engine
 
+	// root router group, all routes registered by *RouterGroup are under it
+	root *RouterGroup
+
 	noRoute  gin.HandlersChain
 	noMethod gin.HandlersChain
 	// global middlewares
@@ -124,6 +192,8 @@ type Server struct {
 	// metadata is a ready-only map during server running, which holds all the route metadata.
 	// It is not thread-safe, should not be modified after server running.
 	metadata *FrozenMap[string, routeMeta]
+	// metaTable is compiled from metadata when frozen, it is used to look up route metadata for each request.
+	metaTable atomic.Pointer[metaTable]
 
 	// os stop signals
 	stopSignals []os.Signal
@@ -141,18 +211,99 @@ func (s *Server) Engine() *gin.Engine {
 
 // Run just run the http server without hooks, you should use *Server.Spin` im most time.
 func (s *Server) Run() error {
-	s.metadata.Frozen()
+	if err := s.prepare(); err != nil {
+		return err
+	}
+	return s.serve()
+}
+
+// prepare freezes metadata, loads tls certificates, configures http2 and listens on all the addresses
+func (s *Server) prepare() error {
+	if err := s.freeze(); err != nil {
+		return err
+	}
+	if err := s.setupTLS(); err != nil {
+		return err
+	}
+	if err := s.setupHTTP2(); err != nil {
+		return err
+	}
+	return s.listen()
+}
+
+// setupHTTP2 configures HTTP/2 over TLS, and wraps the handler of http server for h2c if enabled
+func (s *Server) setupHTTP2() error {
+	if s.options.HTTP2 == nil || s.http2Configured {
+		return nil
+	}
+	h2server := &http2.Server{
+		MaxConcurrentStreams:         s.options.HTTP2.MaxConcurrentStreams,
+		MaxReadFrameSize:             s.options.HTTP2.MaxReadFrameSize,
+		IdleTimeout:                  s.options.HTTP2.IdleTimeout,
+		MaxUploadBufferPerConnection: s.options.HTTP2.MaxUploadBufferPerConnection,
+		MaxUploadBufferPerStream:     s.options.HTTP2.MaxUploadBufferPerStream,
+	}
+	if err := http2.ConfigureServer(s.httpserver, h2server); err != nil {
+		return fmt.Errorf("http2: %w", err)
+	}
+	if s.options.HTTP2.H2C {
+		s.httpserver.Handler = h2c.NewHandler(s.httpserver.Handler, h2server)
+	}
+	s.http2Configured = true
+	return nil
+}
+
+// serve serves on all the bound listeners, it returns after all of them are closed,
+// if any of them fails, the server will be closed.
+func (s *Server) serve() error {
 	if s.options.TLS != nil {
 		slog.InfoContext(s.ctx, "tls certificate has been configured")
-		slog.InfoContext(s.ctx, fmt.Sprintf("server is listiening at %v", s.options.Address))
-		return s.httpserver.ListenAndServeTLS(s.options.TLS.Cert, s.options.TLS.Key)
-	} else {
-		slog.InfoContext(s.ctx, fmt.Sprintf("server is listiening at %v", s.options.Address))
-		return s.httpserver.ListenAndServe()
 	}
+
+	errCh := make(chan error, len(s.bound))
+	for _, l := range s.bound {
+		slog.InfoContext(s.ctx, fmt.Sprintf("server is listening at %s://%s", l.Addr().Network(), l.Addr()))
+		go func() {
+			if s.options.TLS != nil {
+				// certificates are provided by tls config
+				errCh <- s.httpserver.ServeTLS(l, "", "")
+			} else {
+				errCh <- s.httpserver.Serve(l)
+			}
+		}()
+	}
+
+	var err error
+	for range s.bound {
+		if e := <-errCh; err == nil {
+			err = e
+			if !errors.Is(err, http.ErrServerClosed) {
+				_ = s.httpserver.Close()
+			}
+		}
+	}
+	return err
+}
+
+// freeze validates and resolves route metadata, then makes it immutable
+func (s *Server) freeze() error {
+	if s.metadata.IsFrozen() {
+		return nil
+	}
+	if err := s.validateMeta(); err != nil {
+		return err
+	}
+	s.resolveMeta()
+	s.metadata.Frozen()
+	table := compileMetaTable(s.metadata)
+	s.metaTable.Store(&table)
+	return nil
 }
 
 func (s *Server) Shutdown(ctx context.Context) error {
+	if s.stopTLS != nil {
+		s.stopTLS()
+	}
 	return s.httpserver.Shutdown(ctx)
 }
 
@@ -161,49 +312,125 @@ func (s *Server) Spin() error {
 	notifyContext, signalCancel := signal.NotifyContext(s.ctx, s.stopSignals...)
 	defer signalCancel()
 
+	// admin server runs through the whole lifecycle, so that the state could be observed
+	if err := s.startAdmin(); err != nil {
+		return err
+	}
+	defer s.stopAdmin()
+
+	s.setState(StateStarting)
+	defer s.setState(StateStopped)
+	if s.health != nil {
+		s.health.SetReady(false)
+	}
+
 	slog.DebugContext(s.ctx, "hooks before starting are executing")
 	// execute before starting hooks
-	err := s.executeHooks(notifyContext, s.BeforeStarting...)
+	err := s.executeHooks(notifyContext, PhaseBeforeStarting, s.BeforeStarting...)
 	if err != nil {
 		return err
 	}
 
+	// listen before serving, so that startup errors can be returned directly,
+	// and the bound addresses are available in after started hooks.
+	if err := s.prepare(); err != nil {
+		return err
+	}
+
 	runCh := make(chan error)
 
 	go func() {
-		runCh <- s.Run()
+		runCh <- s.serve()
 		close(runCh)
 	}()
 
 	slog.DebugContext(s.ctx, "hooks after starting are executing")
 	// execute after started hooks
-	err = s.executeHooks(notifyContext, s.AfterStarted...)
+	err = s.executeHooks(context.WithValue(notifyContext, addrsKey{}, s.Addrs()), PhaseAfterStarted, s.AfterStarted...)
 	if err != nil {
+		s.abort(runCh)
 		return err
 	}
+	s.setState(StateRunning)
+	if s.health != nil {
+		s.health.SetReady(true)
+	}
 
-	// wait for server closed or stop signal
-	select {
-	case <-notifyContext.Done():
-		slog.InfoContext(s.ctx, fmt.Sprintf("received stop signal, it will shutdown in %s at latest", s.options.MaxShutdownTimeout.String()))
-	case err := <-runCh:
-		if err != nil && !errors.Is(err, http.ErrServerClosed) {
-			slog.ErrorContext(s.ctx, "running failed", slog.Any("error", err))
-		} else {
-			slog.InfoContext(s.ctx, "server closed")
+	// tell the parent process that it is ready if it is started by graceful restart
+	if err := notifyReady(); err != nil {
+		slog.WarnContext(s.ctx, "failed to notify parent process", slog.Any("error", err))
+	}
+
+	restartCh := make(chan os.Signal, 1)
+	if s.restartSignal != nil {
+		signal.Notify(restartCh, s.restartSignal)
+		defer signal.Stop(restartCh)
+	}
+
+	reloadCh := make(chan os.Signal, 1)
+	if s.reloadSignal != nil {
+		signal.Notify(reloadCh, s.reloadSignal)
+		defer signal.Stop(reloadCh)
+	}
+
+	// wait for server closed, stop signal or restart signal
+	var stopped bool
+wait:
+	for {
+		select {
+		case <-notifyContext.Done():
+			slog.InfoContext(s.ctx, fmt.Sprintf("received stop signal, it will shutdown in %s at latest", (s.options.PreStopDelay+s.options.MaxShutdownTimeout).String()))
+			stopped = true
+			break wait
+		case <-restartCh:
+			slog.InfoContext(s.ctx, "received restart signal, starting new process")
+			if err := s.restart(); err != nil {
+				// keep serving if new process can not start
+				slog.ErrorContext(s.ctx, "restart failed", slog.Any("error", err))
+				continue
+			}
+			slog.InfoContext(s.ctx, fmt.Sprintf("new process is ready, it will shutdown in %s at latest", s.options.MaxShutdownTimeout.String()))
+			break wait
+		case <-reloadCh:
+			slog.InfoContext(s.ctx, "received reload signal, reloading config")
+			if _, err := s.Reload(); err != nil {
+				slog.ErrorContext(s.ctx, "reload failed", slog.Any("error", err))
+			}
+		case err := <-runCh:
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				slog.ErrorContext(s.ctx, "running failed", slog.Any("error", err))
+			} else {
+				slog.InfoContext(s.ctx, "server closed")
+			}
+			break wait
 		}
 	}
 
 	// ready to server shutdown
+	s.setState(StateStopping)
+	if s.health != nil {
+		s.health.SetReady(false)
+	}
+
+	// keep serving for a while after readiness turned off, so that load balancers could stop sending requests in time
+	if stopped && s.options.PreStopDelay > 0 {
+		slog.InfoContext(s.ctx, fmt.Sprintf("waiting %s before shutdown", s.options.PreStopDelay.String()))
+		select {
+		case <-time.After(s.options.PreStopDelay):
+		case <-runCh:
+		}
+	}
+
 	shutdownCh := make(chan error)
-	timeoutCtx, shutdownCancel := context.WithTimeout(s.ctx, s.options.MaxShutdownTimeout)
+	// root context may have been canceled, shutdown should not be affected by it
+	timeoutCtx, shutdownCancel := context.WithTimeout(context.WithoutCancel(s.ctx), s.options.MaxShutdownTimeout)
 	defer shutdownCancel()
 
 	_ = s.Shutdown(timeoutCtx)
 
 	go func() {
 		slog.DebugContext(s.ctx, "hooks on shutdown are executing")
-		shutdownCh <- s.executeHooks(timeoutCtx, s.OnShutdown...)
+		shutdownCh <- s.executeHooks(timeoutCtx, PhaseOnShutdown, s.OnShutdown...)
 		close(shutdownCh)
 	}()
 
@@ -224,13 +451,23 @@ func (s *Server) Spin() error {
 	return nil
 }
 
-func (s *Server) executeHooks(ctx context.Context, hooks ...HookFn) error {
-	for _, hook := range hooks {
-		if err := hook(ctx); err != nil {
-			return err
-		}
+// abort shuts down the serving server and executes shutdown hooks when it fails to start,
+// it waits until serving returns so that listeners are closed.
+func (s *Server) abort(runCh <-chan error) {
+	s.setState(StateStopping)
+	timeoutCtx, cancel := context.WithTimeout(context.WithoutCancel(s.ctx), s.options.MaxShutdownTimeout)
+	defer cancel()
+
+	if err := s.Shutdown(timeoutCtx); err != nil {
+		slog.ErrorContext(s.ctx, "shutdown error", slog.Any("error", err))
+	}
+	// drain serving result, the listeners have been closed once it returns
+	for range runCh {
+	}
+
+	if err := s.executeHooks(timeoutCtx, PhaseOnShutdown, s.OnShutdown...); err != nil {
+		slog.ErrorContext(s.ctx, "shutdown error", slog.Any("error", err))
 	}
-	return nil
 }
 
 // applyOptions applies options to http server and engine
@@ -258,6 +495,10 @@ func (s *Server) applyOptions() {
 		s.httpserver.WriteTimeout = s.options.WriteTimeout
 	}
 
+	if s.httpserver.IdleTimeout == 0 {
+		s.httpserver.IdleTimeout = s.options.IdleTimeout
+	}
+
 	if s.httpserver.MaxHeaderBytes == 0 {
 		s.httpserver.MaxHeaderBytes = s.options.MaxHeaderBytes
 	}
@@ -278,8 +519,12 @@ func (s *Server) applyOptions() {
 		s.engine.MaxMultipartMemory = s.options.MaxMultipartMemory
 	}
 
+	if s.metrics != nil {
+		s.httpserver.ConnState = s.metrics.connState(s.httpserver.ConnState)
+	}
+
 	// apply middlewares
-	s.engine.Use(metaDataHandler(s.metadata))
+	s.engine.Use(metaDataHandler(s))
 	s.engine.Use(s.middlewares...)
 	s.engine.NoMethod(s.noMethod...)
 	s.engine.NoRoute(s.noRoute...)
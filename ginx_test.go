@@ -0,0 +1,171 @@
+package ginx
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/ginx-contribs/ginx/constant/protocols"
+	"github.com/ginx-contribs/ginx/pkg/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestH2C(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var proto, upgrade string
+	server := New(
+		WithCtx(ctx),
+		WithAddress("127.0.0.1:0"),
+		WithHTTP2(HTTP2Options{MaxConcurrentStreams: 100, IdleTimeout: time.Minute}),
+		WithH2C(),
+		WithAfterStarted(func(ctx context.Context) error {
+			defer cancel()
+			// prior knowledge
+			client := http.Client{Transport: &http2.Transport{
+				AllowHTTP: true,
+				DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+					return (&net.Dialer{}).DialContext(ctx, network, addr)
+				},
+			}}
+			res, err := client.Get("http://" + AddrsFromCtx(ctx)[0].String() + "/ping")
+			if err != nil {
+				return err
+			}
+			res.Body.Close()
+			proto = res.Proto
+
+			// upgrade from HTTP/1.1
+			conn, err := net.Dial("tcp", AddrsFromCtx(ctx)[0].String())
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+			_, err = conn.Write([]byte("GET /ping HTTP/1.1\r\nHost: ginx\r\nConnection: Upgrade, HTTP2-Settings\r\nUpgrade: h2c\r\nHTTP2-Settings: AAMAAABk\r\n\r\n"))
+			if err != nil {
+				return err
+			}
+			upgrade, err = bufio.NewReader(conn).ReadString('\n')
+			return err
+		}),
+	)
+	server.RouterGroup().GET("/ping", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, ctx.Request.Proto)
+	})
+
+	require.NoError(t, server.Spin())
+	assert.Equal(t, protocols.HTTP20, proto)
+	assert.Equal(t, "HTTP/1.1 101 Switching Protocols\r\n", upgrade)
+}
+
+func TestHTTP2Error(t *testing.T) {
+	// the cipher suites required by HTTP/2 are missing
+	server := New(
+		WithAddress("127.0.0.1:0"),
+		WithHttpServer(&http.Server{TLSConfig: &tls.Config{CipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384}}}),
+		WithHTTP2(HTTP2Options{}),
+	)
+	assert.ErrorContains(t, server.Spin(), "http2")
+}
+
+func TestH2CHandler(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var wrapped bool
+	server := New(WithCtx(ctx), WithAddress("127.0.0.1:0"), WithHTTP2(HTTP2Options{}), WithH2C())
+	handler := server.HttpServer().Handler
+	server.HttpServer().Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		wrapped = true
+		handler.ServeHTTP(w, r)
+	})
+	server.AfterStarted = append(server.AfterStarted, func(ctx context.Context) error {
+		defer cancel()
+		res, err := http.Get("http://" + AddrsFromCtx(ctx)[0].String() + "/ping")
+		if err != nil {
+			return err
+		}
+		return res.Body.Close()
+	})
+
+	require.NoError(t, server.Spin())
+	assert.True(t, wrapped)
+}
+
+func TestPreStopDelay(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	registry := health.New()
+	var (
+		readyBefore, readyDuring bool
+		code                     int
+		done                     = make(chan struct{})
+	)
+	server := New(
+		WithCtx(ctx),
+		WithAddress("127.0.0.1:0"),
+		WithHealth(registry),
+		WithPreStopDelay(time.Millisecond*300),
+		WithAfterStarted(func(ctx context.Context) error {
+			addr := AddrsFromCtx(ctx)[0].String()
+			go func() {
+				defer close(done)
+				readyBefore = registry.Ready()
+				cancel()
+				time.Sleep(time.Millisecond * 100)
+				readyDuring = registry.Ready()
+				// still serving during the delay
+				if res, err := http.Get("http://" + addr + "/ping"); err == nil {
+					res.Body.Close()
+					code = res.StatusCode
+				}
+			}()
+			return nil
+		}),
+	)
+	server.RouterGroup().GET("/ping", func(ctx *gin.Context) {})
+
+	begin := time.Now()
+	require.NoError(t, server.Spin())
+	assert.GreaterOrEqual(t, time.Since(begin), time.Millisecond*300)
+	<-done
+	assert.True(t, readyBefore)
+	assert.False(t, readyDuring)
+	assert.Equal(t, http.StatusOK, code)
+}
+
+func TestAfterStartedError(t *testing.T) {
+	var (
+		addr     string
+		shutdown bool
+		hookErr  = errors.New("after started failed")
+	)
+	server := New(
+		WithAddress("127.0.0.1:0"),
+		WithAfterStarted(func(ctx context.Context) error {
+			addr = AddrsFromCtx(ctx)[0].String()
+			return hookErr
+		}),
+		WithOnShutdown(func(ctx context.Context) error {
+			shutdown = true
+			return nil
+		}),
+	)
+
+	require.ErrorIs(t, server.Spin(), hookErr)
+	assert.True(t, shutdown)
+
+	// the port has been released
+	l, err := net.Listen("tcp", addr)
+	require.NoError(t, err)
+	_ = l.Close()
+}
@@ -0,0 +1,211 @@
+package ginx
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/ginx-contribs/ginx/constant/methods"
+	"github.com/ginx-contribs/ginx/pkg/resp"
+	"reflect"
+	"strings"
+)
+
+// Handle wraps a typed handler function into gin.HandlerFunc. Req is bound from uri, query, header and body
+// according to struct tags, then validated by binding.Validator, the result or error returned by fn is rendered by package resp.
+// Use Typed instead to record request and response types into route metadata.
+func Handle[Req, Res any](fn func(ctx context.Context, req Req) (Res, error)) gin.HandlerFunc {
+	reqType := reflect.TypeFor[Req]()
+
+	return func(ctx *gin.Context) {
+		var req Req
+		// allocate for pointer type
+		if reqType.Kind() == reflect.Pointer {
+			req = reflect.New(reqType.Elem()).Interface().(Req)
+		}
+
+		var ptr any = &req
+		if reqType.Kind() == reflect.Pointer {
+			ptr = req
+		}
+
+		if err := bindRequest(ctx, ptr); err != nil {
+			if defaultValidateHandler != nil {
+				defaultValidateHandler(ctx, ptr, err)
+			} else {
+				resp.Fail(ctx).Error(err).JSON()
+			}
+			return
+		}
+
+		res, err := fn(ctx, req)
+		// response has been written by fn itself
+		if ctx.Writer.Written() {
+			return
+		}
+		if err != nil {
+			resp.InternalError(ctx).Error(err).JSON()
+			return
+		}
+		resp.Of[Res](ctx).Data(res).JSON()
+	}
+}
+
+// TypedHandler is a handler created by Typed, it carries the request and response types.
+type TypedHandler struct {
+	Request     reflect.Type
+	Response    reflect.Type
+	HandlerFunc gin.HandlerFunc
+}
+
+// Typed is same as Handle, but the returned handler carries request and response types,
+// which will be recorded into route metadata with keys MetaRequest and MetaResponse by *RouterGroup.HandleTyped.
+func Typed[Req, Res any](fn func(ctx context.Context, req Req) (Res, error)) TypedHandler {
+	return TypedHandler{
+		Request:     reflect.TypeFor[Req](),
+		Response:    reflect.TypeFor[Res](),
+		HandlerFunc: Handle(fn),
+	}
+}
+
+// meta appends request and response types into metadata if absent
+func (h TypedHandler) meta(meta M) M {
+	// copy on write, do not modify the caller's slice
+	meta = meta[:len(meta):len(meta)]
+	if h.Request != nil && !meta.has(MetaRequest.Name()) {
+		meta = append(meta, MetaRequest.V(h.Request))
+	}
+	if h.Response != nil && !meta.has(MetaResponse.Name()) {
+		meta = append(meta, MetaResponse.V(h.Response))
+	}
+	return meta
+}
+
+// bindRequest binds body first, then binds uri, query and header, so that the values from trusted sources
+// can not be overwritten by body, finally validates the whole struct.
+func bindRequest(ctx *gin.Context, ptr any) error {
+	t := indirectType(reflect.TypeOf(ptr))
+	isStruct := t.Kind() == reflect.Struct
+	// bind them ahead so that validation in body binding could see them
+	if isStruct {
+		if err := bindSources(ctx, ptr, t); err != nil {
+			return err
+		}
+	}
+
+	method := ctx.Request.Method
+	if method != methods.Get && method != methods.Head && ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindWith(ptr, binding.Default(method, ctx.ContentType())); err != nil {
+			return err
+		}
+		if isStruct {
+			// the fields from uri and header must not be set by body, the decoders match names case-insensitively
+			resetTagged(reflect.ValueOf(ptr), "uri", "header")
+			if err := bindSources(ctx, ptr, t); err != nil {
+				return err
+			}
+		}
+	}
+
+	if binding.Validator == nil {
+		return nil
+	}
+	return binding.Validator.ValidateStruct(ptr)
+}
+
+// bindSources binds uri, query and header without validation
+func bindSources(ctx *gin.Context, ptr any, t reflect.Type) error {
+	if hasTag(t, "uri") {
+		params := make(map[string][]string, len(ctx.Params))
+		for _, param := range ctx.Params {
+			params[param.Key] = []string{param.Value}
+		}
+		if err := binding.MapFormWithTag(ptr, params, "uri"); err != nil {
+			return err
+		}
+	}
+
+	if hasTag(t, "form") {
+		if err := binding.MapFormWithTag(ptr, ctx.Request.URL.Query(), "form"); err != nil {
+			return err
+		}
+	}
+
+	if names := tagValues(t, "header"); len(names) > 0 {
+		header := make(map[string][]string, len(names))
+		for _, name := range names {
+			// header key is case-insensitive
+			header[name] = ctx.Request.Header.Values(name)
+		}
+		if err := binding.MapFormWithTag(ptr, header, "header"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resetTagged sets the struct fields which have any of the tags to zero value, include nested struct fields.
+func resetTagged(v reflect.Value, tags ...string) {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct || v.Type().PkgPath() == "time" {
+		return
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+		fv := v.Field(i)
+		tagged := false
+		for _, tag := range tags {
+			if value, ok := field.Tag.Lookup(tag); ok && value != "-" {
+				tagged = true
+				break
+			}
+		}
+		if tagged {
+			if fv.CanSet() {
+				fv.SetZero()
+			}
+			continue
+		}
+		resetTagged(fv, tags...)
+	}
+}
+
+// hasTag reports whether any field of struct has the tag
+func hasTag(t reflect.Type, tag string) bool {
+	return len(tagValues(t, tag)) > 0
+}
+
+// tagValues returns names declared in the tag of struct fields, include nested struct fields.
+func tagValues(t reflect.Type, tag string) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if value, ok := field.Tag.Lookup(tag); ok && value != "-" {
+			name, _, _ := strings.Cut(value, ",")
+			if name == "" {
+				name = field.Name
+			}
+			names = append(names, name)
+			continue
+		}
+		if ft := indirectType(field.Type); ft.Kind() == reflect.Struct && ft.NumField() > 0 && ft.PkgPath() != "time" {
+			names = append(names, tagValues(ft, tag)...)
+		}
+	}
+	return names
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
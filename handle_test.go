@@ -0,0 +1,133 @@
+package ginx
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/ginx-contribs/ginx/constant/status"
+	"github.com/ginx-contribs/ginx/pkg/resp"
+	"github.com/ginx-contribs/ginx/pkg/resp/statuserr"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type updateReq struct {
+	Id    int    `uri:"id" binding:"required"`
+	Token string `header:"x-token" binding:"required"`
+	Lang  string `form:"lang"`
+	Name  string `json:"name" binding:"required"`
+}
+
+type updateRes struct {
+	Id   int    `json:"id"`
+	Lang string `json:"lang"`
+	Name string `json:"name"`
+}
+
+func TestHandle(t *testing.T) {
+	server := New()
+	root := server.RouterGroup()
+	root.HandleTyped(http.MethodPut, "/user/:id", nil, Typed(func(ctx context.Context, req *updateReq) (updateRes, error) {
+		if req.Name == "admin" {
+			return updateRes{}, statuserr.Forbidden(errors.New("permission denied"))
+		}
+		return updateRes{Id: req.Id, Lang: req.Lang, Name: req.Name}, nil
+	}))
+
+	do := func(body string, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/user/1?lang=en", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Token", token)
+		recorder := httptest.NewRecorder()
+		server.Engine().ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	recorder := do(`{"name":"jack"}`, "token")
+	assert.Equal(t, status.OK.Code(), recorder.Code)
+	assert.JSONEq(t, `{"code":200,"data":{"id":1,"lang":"en","name":"jack"}}`, recorder.Body.String())
+
+	recorder = do(`{"name":"jack"}`, "")
+	assert.Equal(t, status.BadRequest.Code(), recorder.Code)
+
+	recorder = do(`{"name":"admin"}`, "token")
+	assert.Equal(t, status.Forbidden.Code(), recorder.Code)
+
+	root.Walk(func(info RouteInfo) {
+		if info.IsGroup {
+			return
+		}
+		assert.Equal(t, reflect.TypeOf(&updateReq{}), MetaRequest.ShouldGet(info.Meta))
+		assert.Equal(t, reflect.TypeOf(updateRes{}), MetaResponse.ShouldGet(info.Meta))
+	})
+}
+
+func TestHandleBodyOverride(t *testing.T) {
+	server := New()
+	var got *updateReq
+	server.RouterGroup().PUT("/user/:id", Handle(func(ctx context.Context, req *updateReq) (updateRes, error) {
+		got = req
+		return updateRes{Id: req.Id}, nil
+	}))
+
+	do := func(body string, token string) *httptest.ResponseRecorder {
+		got = nil
+		req := httptest.NewRequest(http.MethodPut, "/user/1", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("X-Token", token)
+		}
+		recorder := httptest.NewRecorder()
+		server.Engine().ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	// body can not overwrite path param
+	recorder := do(`{"name":"jack","Id":99}`, "token")
+	assert.Equal(t, status.OK.Code(), recorder.Code)
+	if assert.NotNil(t, got) {
+		assert.Equal(t, 1, got.Id)
+		assert.Equal(t, "token", got.Token)
+	}
+
+	// body can not overwrite header
+	recorder = do(`{"name":"jack","Id":99,"Token":"forged"}`, "token")
+	assert.Equal(t, status.OK.Code(), recorder.Code)
+	if assert.NotNil(t, got) {
+		assert.Equal(t, 1, got.Id)
+		assert.Equal(t, "token", got.Token)
+	}
+
+	// body can not provide the required header
+	recorder = do(`{"name":"jack","Id":99,"Token":"forged"}`, "")
+	assert.Equal(t, status.BadRequest.Code(), recorder.Code)
+	assert.Nil(t, got)
+}
+
+func TestShouldBindPage(t *testing.T) {
+	server := New()
+	var pages []resp.PageRequest
+	server.RouterGroup().GET("/items", func(ctx *gin.Context) {
+		page, err := ShouldBindPageWith(ctx, resp.PageOptions{MaxLimit: 50})
+		if err != nil {
+			return
+		}
+		pages = append(pages, page)
+	})
+
+	for _, target := range []string{"/items", "/items?offset=10&limit=500", "/items?cursor=abc"} {
+		server.Engine().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+	assert.Equal(t, []resp.PageRequest{{Limit: 20}, {Offset: 10, Limit: 50}, {Limit: 20, Cursor: "abc"}}, pages)
+
+	// invalid page responds the reason
+	recorder := httptest.NewRecorder()
+	server.Engine().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/items?offset=-1", nil))
+	assert.Equal(t, status.BadRequest.Code(), recorder.Code)
+	assert.JSONEq(t, `{"code":400,"error":"invalid page: negative offset -1"}`, recorder.Body.String())
+	assert.Len(t, pages, 3)
+}
@@ -0,0 +1,249 @@
+package ginx
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/ginx-contribs/ginx/pkg/resp"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// well-known metadata keys, they are recognized by tools like api document generator.
+var (
+	// MetaSummary is a short summary of what the route does
+	MetaSummary = NewKey[string]("summary", WithMerge(MergeRemove))
+	// MetaDescription is a verbose explanation of the route
+	MetaDescription = NewKey[string]("description", WithMerge(MergeRemove))
+	// MetaTags is used for logical grouping of routes, tags of group will be merged into its routes.
+	// A plain string is accepted as a single tag.
+	MetaTags = NewKey[[]string]("tags", WithMerge(MergeUnion))
+	// MetaOperationId is a unique id used to identify the route
+	MetaOperationId = NewKey[string]("operationId", WithMerge(MergeRemove))
+	// MetaRequest is the request type of route, value is reflect.Type or an instance of the type
+	MetaRequest = NewKey[any]("request", WithMerge(MergeRemove))
+	// MetaResponse is the response type of route, value is reflect.Type or an instance of the type
+	MetaResponse = NewKey[any]("response", WithMerge(MergeRemove))
+	// MetaDeprecated declares the route is deprecated
+	MetaDeprecated = NewKey[bool]("deprecated")
+	// MetaEnvelope is the envelope of responses rendered by package resp, like resp.ProblemEnvelope
+	MetaEnvelope = NewKey[resp.Envelope]("envelope")
+)
+
+// declaredKeys holds information of all declared keys, key is the name.
+var declaredKeys sync.Map
+
+// keyInfo is the declaration of a key
+type keyInfo struct {
+	typ   reflect.Type
+	merge MergeStrategy
+}
+
+// KeyOption configures the declaration of key
+type KeyOption func(info *keyInfo)
+
+// WithMerge declares how the value of key is inherited from router group, default is MergeOverride.
+func WithMerge(strategy MergeStrategy) KeyOption {
+	return func(info *keyInfo) {
+		info.merge = strategy
+	}
+}
+
+// NewKey declares a typed metadata key, it panics if the name has been declared with another type or merge strategy.
+// Declaring the same name with the same type is allowed, so different packages can share a key.
+func NewKey[T any](name string, opts ...KeyOption) Key[T] {
+	info := keyInfo{typ: reflect.TypeFor[T]()}
+	for _, opt := range opts {
+		opt(&info)
+	}
+	if err := info.merge.check(info.typ); err != nil {
+		panic(fmt.Sprintf("metadata key %q: %s", name, err))
+	}
+	if v, loaded := declaredKeys.LoadOrStore(name, info); loaded {
+		if declared := v.(keyInfo); declared != info {
+			panic(fmt.Sprintf("metadata key %q has been declared with type %v and %s strategy, but got %v and %s strategy",
+				name, declared.typ, declared.merge, info.typ, info.merge))
+		}
+	}
+	return Key[T]{name: name}
+}
+
+// Key is a typed metadata key, it is type-safe to build and read metadata by key.
+// Values set in other ways are checked by the declared type when *Server.Run.
+type Key[T any] struct {
+	name string
+}
+
+// Name returns name of key
+func (k Key[T]) Name() string {
+	return k.name
+}
+
+// V returns a metadata item with the key, it is used to build M.
+func (k Key[T]) V(val T) V {
+	return V{Key: k.name, Val: val}
+}
+
+// Unset returns a metadata item which denies the value inherited from router group.
+func (k Key[T]) Unset() V {
+	return V{Key: k.name, Val: unset{}}
+}
+
+// Get returns value of the key in metadata, numeric value will be widened into T if lossless,
+// returns false if key not found or value type does not match.
+func (k Key[T]) Get(m MetaData) (T, bool) {
+	var zero T
+	v, ok := m.m[k.name]
+	if !ok {
+		return zero, false
+	}
+	if t, ok := v.(T); ok {
+		return t, true
+	}
+	rv, ok := convertValue(v, reflect.TypeFor[T]())
+	if !ok {
+		return zero, false
+	}
+	return rv.Interface().(T), true
+}
+
+// ShouldGet returns value of the key in metadata, returns zero value if not found.
+func (k Key[T]) ShouldGet(m MetaData) T {
+	t, _ := k.Get(m)
+	return t
+}
+
+// FromCtx returns value of the key in metadata of the current route
+func (k Key[T]) FromCtx(ctx *gin.Context) (T, bool) {
+	return k.Get(MetaFromCtx(ctx))
+}
+
+// convertValue converts v into the type t by following rules:
+//  1. v is assignable to t
+//  2. v is numeric and can be widened to t without loss, e.g. int8 -> int64, uint16 -> int32, int32 -> float64.
+//  3. t is slice and v is assignable to its element, v is wrapped into a single element slice, e.g. string -> []string.
+func convertValue(v any, t reflect.Type) (reflect.Value, bool) {
+	if v == nil {
+		switch t.Kind() {
+		case reflect.Interface, reflect.Pointer, reflect.Slice, reflect.Map, reflect.Func, reflect.Chan:
+			return reflect.Zero(t), true
+		}
+		return reflect.Value{}, false
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Type().AssignableTo(t) {
+		return rv, true
+	}
+	if canWiden(rv.Kind(), t.Kind()) {
+		return rv.Convert(t), true
+	}
+	if t.Kind() == reflect.Slice && rv.Type().AssignableTo(t.Elem()) {
+		slice := reflect.MakeSlice(t, 1, 1)
+		slice.Index(0).Set(rv)
+		return slice, true
+	}
+	return reflect.Value{}, false
+}
+
+// canWiden reports whether numeric kind from can be converted into kind to without loss
+func canWiden(from, to reflect.Kind) bool {
+	fromBits, fromClass := numericKind(from)
+	toBits, toClass := numericKind(to)
+	if fromClass == notNumeric || toClass == notNumeric {
+		return false
+	}
+
+	switch toClass {
+	case signedInt:
+		switch fromClass {
+		case signedInt:
+			return fromBits <= toBits
+		case unsignedInt:
+			return fromBits < toBits
+		}
+	case unsignedInt:
+		return fromClass == unsignedInt && fromBits <= toBits
+	case float:
+		switch fromClass {
+		case float:
+			return fromBits <= toBits
+		default:
+			// integer can be represented exactly if it fits into mantissa
+			mantissa := 24
+			if toBits == 64 {
+				mantissa = 53
+			}
+			return fromBits < mantissa
+		}
+	}
+	return false
+}
+
+const (
+	notNumeric = iota
+	signedInt
+	unsignedInt
+	float
+)
+
+func numericKind(kind reflect.Kind) (bits int, class int) {
+	switch kind {
+	case reflect.Int:
+		return strconv.IntSize, signedInt
+	case reflect.Int8:
+		return 8, signedInt
+	case reflect.Int16:
+		return 16, signedInt
+	case reflect.Int32:
+		return 32, signedInt
+	case reflect.Int64:
+		return 64, signedInt
+	case reflect.Uint, reflect.Uintptr:
+		return strconv.IntSize, unsignedInt
+	case reflect.Uint8:
+		return 8, unsignedInt
+	case reflect.Uint16:
+		return 16, unsignedInt
+	case reflect.Uint32:
+		return 32, unsignedInt
+	case reflect.Uint64:
+		return 64, unsignedInt
+	case reflect.Float32:
+		return 32, float
+	case reflect.Float64:
+		return 64, float
+	}
+	return 0, notNumeric
+}
+
+// validateMeta checks values of all route metadata by the declared key types,
+// converted values are stored back, so they are merged and read as the declared type.
+func (s *Server) validateMeta() error {
+	var errs []error
+	s.metadata.Range(func(_ string, meta routeMeta) {
+		for k, v := range meta.MetaData.m {
+			declared, ok := declaredKeys.Load(k)
+			if !ok || isUnset(v) {
+				continue
+			}
+			typ := declared.(keyInfo).typ
+			rv, ok := convertValue(v, typ)
+			if !ok {
+				route := meta.FullPath
+				if meta.Method != "" {
+					route = meta.Method + " " + route
+				}
+				errs = append(errs, fmt.Errorf("route %s: metadata %q expects %v, but got %T", route, k, typ, v))
+			} else if v != nil && rv.Type() != reflect.TypeOf(v) {
+				meta.MetaData.m[k] = rv.Interface()
+			}
+		}
+	})
+	slices.SortFunc(errs, func(a, b error) int {
+		return strings.Compare(a.Error(), b.Error())
+	})
+	return errors.Join(errs...)
+}
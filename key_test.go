@@ -0,0 +1,103 @@
+package ginx
+
+import (
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestKey(t *testing.T) {
+	limit := NewKey[int64]("test.limit")
+	ratio := NewKey[float64]("test.ratio")
+	small := NewKey[int8]("test.small")
+	timeout := NewKey[time.Duration]("test.timeout")
+
+	meta := M{{Key: limit.Name(), Val: 5}, {Key: ratio.Name(), Val: int32(3)}, {Key: small.Name(), Val: 300}, timeout.V(time.Second)}.build()
+
+	// int -> int64
+	l, ok := limit.Get(meta)
+	assert.True(t, ok)
+	assert.EqualValues(t, 5, l)
+
+	// int32 -> float64
+	r, ok := ratio.Get(meta)
+	assert.True(t, ok)
+	assert.EqualValues(t, 3, r)
+
+	// int -> int8 is narrowing
+	_, ok = small.Get(meta)
+	assert.False(t, ok)
+
+	assert.Equal(t, time.Second, timeout.ShouldGet(meta))
+
+	assert.Panics(t, func() {
+		NewKey[string]("test.limit")
+	})
+	assert.NotPanics(t, func() {
+		NewKey[int64]("test.limit")
+	})
+}
+
+func TestCanWiden(t *testing.T) {
+	var i8 int8
+	var u32 uint32
+	var i64 int64
+	var f32 float32
+	assert.True(t, canWidenValue(i8, i64))
+	assert.True(t, canWidenValue(u32, i64))
+	assert.True(t, canWidenValue(u32, float64(0)))
+	assert.True(t, canWidenValue(f32, float64(0)))
+	assert.False(t, canWidenValue(i64, f32))
+	assert.False(t, canWidenValue(i8, u32))
+	assert.False(t, canWidenValue(i64, i8))
+}
+
+func canWidenValue(from, to any) bool {
+	_, ok := convertValue(from, reflect.TypeOf(to))
+	return ok
+}
+
+func TestValidateMeta(t *testing.T) {
+	key := NewKey[string]("test.name")
+	server := New()
+	root := server.RouterGroup()
+	root.MGET("a", M{key.V("a")})
+	assert.Nil(t, server.validateMeta())
+	root.MGET("b", M{{Key: key.Name(), Val: 1}})
+	assert.ErrorContains(t, server.validateMeta(), `route GET /b: metadata "test.name" expects string, but got int`)
+}
+
+func TestStringTags(t *testing.T) {
+	server := New()
+	root := server.RouterGroup()
+	api := root.MGroup("api", M{MetaTags.V([]string{"api"})})
+	api.MGET("user", M{{Key: MetaTags.Name(), Val: "user"}})
+	root.MGET("ping", M{{Key: MetaTags.Name(), Val: "ping"}})
+
+	tagsOf := func(path string) []string {
+		var tags []string
+		root.Walk(func(info RouteInfo) {
+			if info.FullPath == path {
+				tags = MetaTags.ShouldGet(info.Meta)
+			}
+		})
+		return tags
+	}
+
+	// resolved on the fly
+	assert.Equal(t, []string{"api", "user"}, tagsOf("/api/user"))
+	assert.Equal(t, []string{"ping"}, tagsOf("/ping"))
+
+	// resolved at freeze time
+	assert.Nil(t, server.freeze())
+	assert.Equal(t, []string{"api", "user"}, tagsOf("/api/user"))
+	assert.Equal(t, []string{"ping"}, tagsOf("/ping"))
+
+	// stored as the declared type
+	root.Walk(func(info RouteInfo) {
+		if info.FullPath == "/ping" {
+			assert.IsType(t, []string{}, info.Meta.m[MetaTags.Name()])
+		}
+	})
+}
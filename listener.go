@@ -0,0 +1,205 @@
+package ginx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"syscall"
+	"time"
+)
+
+// ListenerOptions declares an address to listen on
+type ListenerOptions struct {
+	// tcp, tcp4, tcp6 or unix, default is tcp
+	Network string `mapstructure:"network"`
+	// tcp address in the form "host:port", or unix socket path
+	Address string `mapstructure:"address"`
+	// file mode of unix socket, zero means keeping the default mode
+	FileMode os.FileMode `mapstructure:"fileMode"`
+}
+
+func (l ListenerOptions) String() string {
+	return l.Network + "://" + l.Address
+}
+
+// namedListener is a bound listener, name is used to match the inherited listener after restart
+type namedListener struct {
+	net.Listener
+	name string
+}
+
+type addrsKey struct{}
+
+// AddrsFromCtx returns the addresses the server is actually listening on, it is available in AfterStarted hooks.
+func AddrsFromCtx(ctx context.Context) []net.Addr {
+	addrs, _ := ctx.Value(addrsKey{}).([]net.Addr)
+	return addrs
+}
+
+// Addrs returns the addresses the server is actually listening on, it returns nil if server is not listening.
+func (s *Server) Addrs() []net.Addr {
+	addrs := make([]net.Addr, 0, len(s.bound))
+	for _, l := range s.bound {
+		addrs = append(addrs, l.Addr())
+	}
+	return addrs
+}
+
+// listenerOptions returns all the addresses need to be listened, includes Options.Address and Options.Listeners
+func (s *Server) listenerOptions() []ListenerOptions {
+	var lopts []ListenerOptions
+	if s.options.Address != "" {
+		lopts = append(lopts, ListenerOptions{Network: "tcp", Address: s.options.Address})
+	}
+	for _, lopt := range s.options.Listeners {
+		if lopt.Network == "" {
+			lopt.Network = "tcp"
+		}
+		lopts = append(lopts, lopt)
+	}
+	return lopts
+}
+
+// listen creates all the listeners, the listeners inherited from parent process or systemd are preferred,
+// if any of them fails, the created ones will be closed.
+func (s *Server) listen() error {
+	if len(s.bound) > 0 {
+		return nil
+	}
+
+	if err := s.loadInherited(); err != nil {
+		return err
+	}
+	inherited := s.inherited
+	s.inherited = nil
+	// the admin listener is not served by main server even if admin server is disabled
+	if l, ok := takeInheritedByName(&inherited, adminListenerName); ok {
+		_ = l.Close()
+	}
+
+	bound := make([]namedListener, 0, len(s.listeners)+len(s.options.Listeners)+len(inherited)+1)
+	for _, lopt := range s.listenerOptions() {
+		if l, ok := takeInherited(&inherited, lopt); ok {
+			bound = append(bound, l)
+			continue
+		}
+
+		l, err := listen(lopt)
+		if err != nil {
+			for _, b := range append(bound, inherited...) {
+				_ = b.Close()
+			}
+			return fmt.Errorf("listen %s: %w", lopt, err)
+		}
+		bound = append(bound, namedListener{Listener: l, name: lopt.String()})
+	}
+
+	for _, l := range s.listeners {
+		bound = append(bound, namedListener{Listener: l, name: "listener://" + l.Addr().String()})
+	}
+	// the rest inherited listeners are not declared in options, serve on them as well
+	bound = append(bound, inherited...)
+
+	if len(bound) == 0 {
+		return errors.New("no listener available")
+	}
+	s.bound = bound
+	return nil
+}
+
+// loadInherited loads the listeners passed by parent process or systemd, it only takes effect at the first call,
+// since the environment variables are unset after loading.
+func (s *Server) loadInherited() error {
+	if s.inheritedLoaded {
+		return nil
+	}
+	inherited, err := inheritedListeners()
+	if err != nil {
+		return fmt.Errorf("inherit listeners: %w", err)
+	}
+	s.inherited = inherited
+	s.inheritedLoaded = true
+	return nil
+}
+
+// takeInheritedByName finds the inherited listener by name, then removes it from the list.
+func takeInheritedByName(inherited *[]namedListener, name string) (namedListener, bool) {
+	for i, l := range *inherited {
+		if l.name == name {
+			*inherited = append((*inherited)[:i], (*inherited)[i+1:]...)
+			return l, true
+		}
+	}
+	return namedListener{}, false
+}
+
+// takeInherited finds the inherited listener which matches the options by name or address, then removes it from the list.
+func takeInherited(inherited *[]namedListener, lopt ListenerOptions) (namedListener, bool) {
+	for _, match := range []func(l namedListener) bool{
+		func(l namedListener) bool { return l.name == lopt.String() },
+		func(l namedListener) bool { return sameAddr(l.Addr(), lopt) },
+	} {
+		for i, l := range *inherited {
+			if match(l) {
+				*inherited = append((*inherited)[:i], (*inherited)[i+1:]...)
+				return l, true
+			}
+		}
+	}
+	return namedListener{}, false
+}
+
+// sameAddr reports whether the addr is the address declared in options
+func sameAddr(addr net.Addr, lopt ListenerOptions) bool {
+	if lopt.Network == "unix" {
+		return addr.Network() == "unix" && addr.String() == lopt.Address
+	}
+
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+	resolved, err := net.ResolveTCPAddr(lopt.Network, lopt.Address)
+	if err != nil || resolved.Port == 0 || resolved.Port != tcpAddr.Port {
+		return false
+	}
+	if resolved.IP == nil || resolved.IP.IsUnspecified() {
+		return tcpAddr.IP.IsUnspecified()
+	}
+	return resolved.IP.Equal(tcpAddr.IP)
+}
+
+func listen(lopt ListenerOptions) (net.Listener, error) {
+	if lopt.Network != "unix" {
+		return net.Listen(lopt.Network, lopt.Address)
+	}
+
+	// remove the stale socket file left by last running, but never take over the one in use
+	if info, err := os.Stat(lopt.Address); err == nil && info.Mode().Type() == fs.ModeSocket {
+		conn, err := net.DialTimeout("unix", lopt.Address, time.Second)
+		if err == nil {
+			_ = conn.Close()
+		}
+		if !errors.Is(err, syscall.ECONNREFUSED) {
+			return nil, &net.OpError{Op: "listen", Net: "unix", Addr: &net.UnixAddr{Name: lopt.Address, Net: "unix"}, Err: os.NewSyscallError("bind", syscall.EADDRINUSE)}
+		}
+		if err := os.Remove(lopt.Address); err != nil {
+			return nil, err
+		}
+	}
+
+	l, err := net.Listen("unix", lopt.Address)
+	if err != nil {
+		return nil, err
+	}
+	if lopt.FileMode != 0 {
+		if err := os.Chmod(lopt.Address, lopt.FileMode); err != nil {
+			_ = l.Close()
+			return nil, err
+		}
+	}
+	return l, nil
+}
@@ -0,0 +1,97 @@
+package ginx
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestMultiListener(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "ginx.sock")
+	custom, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		served []string
+		mode   os.FileMode
+	)
+	server := New(
+		WithCtx(ctx),
+		WithAddress("127.0.0.1:0"),
+		WithListen("unix", sock, 0o600),
+		WithListener(custom),
+		WithAfterStarted(func(ctx context.Context) error {
+			defer cancel()
+			if info, err := os.Stat(sock); err == nil {
+				mode = info.Mode().Perm()
+			}
+			for _, addr := range AddrsFromCtx(ctx) {
+				client := http.Client{Transport: &http.Transport{
+					DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+						return (&net.Dialer{}).DialContext(ctx, addr.Network(), addr.String())
+					},
+				}}
+				res, err := client.Get("http://ginx/ping")
+				if err != nil {
+					return err
+				}
+				body, _ := io.ReadAll(res.Body)
+				res.Body.Close()
+				served = append(served, string(body))
+			}
+			return nil
+		}),
+	)
+	server.RouterGroup().GET("/ping", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, "pong")
+	})
+
+	require.NoError(t, server.Spin())
+	assert.Equal(t, []string{"pong", "pong", "pong"}, served)
+
+	addrs := server.Addrs()
+	if assert.Len(t, addrs, 3) {
+		assert.Equal(t, "unix", addrs[1].Network())
+		assert.Equal(t, custom.Addr(), addrs[2])
+	}
+	assert.Equal(t, os.FileMode(0o600), mode)
+}
+
+func TestListenError(t *testing.T) {
+	occupied, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer occupied.Close()
+
+	server := New(WithAddress("127.0.0.1:0"), WithListen("tcp", occupied.Addr().String(), 0))
+	err = server.Spin()
+	assert.Error(t, err)
+	assert.Empty(t, server.Addrs())
+}
+
+func TestListenUnixInUse(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "ginx.sock")
+	live, err := net.Listen("unix", sock)
+	require.NoError(t, err)
+
+	// the socket of live process is not taken over
+	_, err = listen(ListenerOptions{Network: "unix", Address: sock})
+	assert.ErrorIs(t, err, syscall.EADDRINUSE)
+
+	// the stale socket file is removed
+	live.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, live.Close())
+	l, err := listen(ListenerOptions{Network: "unix", Address: sock})
+	require.NoError(t, err)
+	_ = l.Close()
+}
@@ -7,32 +7,59 @@ import (
 
 // FrozenMap is a thread-safe map, write operations will cause panic after map is frozen.
 type FrozenMap[K comparable, V any] struct {
-	readOnly atomic.Bool
+	// snapshot is a plain map copied from m when frozen, reading it does not need any lock.
+	snapshot atomic.Pointer[map[K]V]
 	m        cmap.ConcurrentMap[K, V]
 }
 
-// Frozen make map be immutable
+// Frozen make map be immutable, entries will be copied into a plain map, then all the reads are lock-free.
 func (r *FrozenMap[K, V]) Frozen() {
-	if r.readOnly.Load() == false {
-		r.readOnly.Store(true)
+	if r.IsFrozen() {
+		return
 	}
+	snapshot := make(map[K]V, r.m.Count())
+	r.m.IterCb(func(k K, v V) {
+		snapshot[k] = v
+	})
+	r.snapshot.Store(&snapshot)
+}
+
+// IsFrozen reports whether the map is immutable
+func (r *FrozenMap[K, V]) IsFrozen() bool {
+	return r.snapshot.Load() != nil
 }
 
 func (r *FrozenMap[K, V]) Set(k K, v V) {
-	if r.readOnly.Load() {
+	if r.IsFrozen() {
 		panic("map is frozen, write operations is not permitted")
 	}
 	r.m.Set(k, v)
 }
 
 func (r *FrozenMap[K, V]) Get(k K) (V, bool) {
+	if snapshot := r.snapshot.Load(); snapshot != nil {
+		v, ok := (*snapshot)[k]
+		return v, ok
+	}
 	return r.m.Get(k)
 }
 
-func (r *FrozenMap[K, V]) Del(k K) {
-	r.m.Remove(k)
+// Del deletes the entry and reports whether it is deleted, it is a no-op if the map is frozen,
+// since the frozen entries are read without lock.
+func (r *FrozenMap[K, V]) Del(k K) bool {
+	if r.IsFrozen() {
+		return false
+	}
+	_, ok := r.m.Pop(k)
+	return ok
 }
 
 func (r *FrozenMap[K, V]) Range(fn func(K, V)) {
+	if snapshot := r.snapshot.Load(); snapshot != nil {
+		for k, v := range *snapshot {
+			fn(k, v)
+		}
+		return
+	}
 	r.m.IterCb(fn)
 }
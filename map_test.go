@@ -0,0 +1,28 @@
+package ginx
+
+import (
+	cmap "github.com/orcaman/concurrent-map/v2"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFrozenMap(t *testing.T) {
+	m := &FrozenMap[string, int]{m: cmap.New[int]()}
+	m.Set("a", 1)
+	m.Set("b", 2)
+	assert.True(t, m.Del("b"))
+	assert.False(t, m.Del("b"))
+	m.Frozen()
+
+	v, ok := m.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+	_, ok = m.Get("b")
+	assert.False(t, ok)
+
+	// write operations are not permitted after frozen
+	assert.Panics(t, func() { m.Set("c", 3) })
+	assert.False(t, m.Del("a"))
+	_, ok = m.Get("a")
+	assert.True(t, ok)
+}
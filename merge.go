@@ -0,0 +1,182 @@
+package ginx
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// MergeStrategy decides how the value of key declared in router group is inherited by its subgroups and routes.
+type MergeStrategy int
+
+const (
+	// MergeOverride inherits the value of parent if child does not declare the key, otherwise uses the child's.
+	MergeOverride MergeStrategy = iota
+	// MergeAppend concatenates slice of parent and slice of child.
+	MergeAppend
+	// MergeUnion concatenates slice of parent and slice of child, duplicate elements will be removed.
+	MergeUnion
+	// MergeDeep merges map of parent and map of child recursively, value of child wins on conflict.
+	MergeDeep
+	// MergeRemove never inherits the value of parent, the value only applies to where it is declared.
+	MergeRemove
+)
+
+func (s MergeStrategy) String() string {
+	switch s {
+	case MergeOverride:
+		return "override"
+	case MergeAppend:
+		return "append"
+	case MergeUnion:
+		return "union"
+	case MergeDeep:
+		return "deep-merge"
+	case MergeRemove:
+		return "remove"
+	}
+	return fmt.Sprintf("unknown(%d)", int(s))
+}
+
+// check reports whether the strategy is applicable to the type
+func (s MergeStrategy) check(typ reflect.Type) error {
+	switch s {
+	case MergeOverride, MergeRemove:
+	case MergeAppend, MergeUnion:
+		if typ.Kind() != reflect.Slice {
+			return fmt.Errorf("%s strategy expects slice type, but got %v", s, typ)
+		}
+	case MergeDeep:
+		if typ.Kind() != reflect.Map {
+			return fmt.Errorf("%s strategy expects map type, but got %v", s, typ)
+		}
+	default:
+		return fmt.Errorf("unknown merge strategy %d", int(s))
+	}
+	return nil
+}
+
+// mergeStrategyOf returns merge strategy of the key, undeclared key uses MergeOverride.
+func mergeStrategyOf(key string) MergeStrategy {
+	if v, ok := declaredKeys.Load(key); ok {
+		return v.(keyInfo).merge
+	}
+	return MergeOverride
+}
+
+// unset is a placeholder value which denies the inherited value
+type unset struct{}
+
+func isUnset(v any) bool {
+	_, ok := v.(unset)
+	return ok
+}
+
+// mergeMeta returns a new metadata which child inherits from parent by merge strategy of keys,
+// neither parent nor child will be modified.
+func mergeMeta(parent, child MetaData) MetaData {
+	if len(parent.m) == 0 && len(child.m) == 0 {
+		return emptyMetaData
+	}
+
+	merged := MetaData{m: make(map[string]any, len(parent.m)+len(child.m))}
+	for k, v := range child.m {
+		if !isUnset(v) {
+			merged.m[k] = v
+		}
+	}
+
+	for k, pv := range parent.m {
+		strategy := mergeStrategyOf(k)
+		if strategy == MergeRemove {
+			continue
+		}
+		cv, declared := child.m[k]
+		if !declared {
+			merged.m[k] = pv
+		} else if !isUnset(cv) {
+			merged.m[k] = mergeValue(strategy, pv, cv)
+		}
+	}
+	return merged
+}
+
+// mergeValue merges value of parent and child, returns child if types of them are not applicable for the strategy.
+func mergeValue(strategy MergeStrategy, parent, child any) any {
+	pv, cv := reflect.ValueOf(parent), reflect.ValueOf(child)
+	if !pv.IsValid() || !cv.IsValid() {
+		return child
+	}
+	// single element of child slice, like a plain string of tags
+	if pv.Kind() == reflect.Slice && cv.Type() != pv.Type() {
+		if converted, ok := convertValue(child, pv.Type()); ok {
+			cv = converted
+			child = cv.Interface()
+		}
+	}
+	if pv.Type() != cv.Type() {
+		return child
+	}
+
+	switch strategy {
+	case MergeAppend, MergeUnion:
+		if pv.Kind() != reflect.Slice {
+			return child
+		}
+		merged := reflect.MakeSlice(pv.Type(), 0, pv.Len()+cv.Len())
+		for _, src := range []reflect.Value{pv, cv} {
+			for i := 0; i < src.Len(); i++ {
+				elem := src.Index(i)
+				if strategy == MergeUnion && containsValue(merged, elem) {
+					continue
+				}
+				merged = reflect.Append(merged, elem)
+			}
+		}
+		return merged.Interface()
+	case MergeDeep:
+		if pv.Kind() != reflect.Map {
+			return child
+		}
+		return mergeMap(pv, cv).Interface()
+	}
+	return child
+}
+
+func containsValue(slice reflect.Value, elem reflect.Value) bool {
+	for i := 0; i < slice.Len(); i++ {
+		if reflect.DeepEqual(slice.Index(i).Interface(), elem.Interface()) {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeMap merges two maps with the same type recursively
+func mergeMap(parent, child reflect.Value) reflect.Value {
+	merged := reflect.MakeMapWithSize(parent.Type(), parent.Len()+child.Len())
+	iter := parent.MapRange()
+	for iter.Next() {
+		merged.SetMapIndex(iter.Key(), iter.Value())
+	}
+
+	iter = child.MapRange()
+	for iter.Next() {
+		cv := iter.Value()
+		pv := merged.MapIndex(iter.Key())
+		if pv.IsValid() {
+			pm, cm := indirectInterface(pv), indirectInterface(cv)
+			if pm.Kind() == reflect.Map && cm.Kind() == reflect.Map && pm.Type() == cm.Type() {
+				cv = mergeMap(pm, cm)
+			}
+		}
+		merged.SetMapIndex(iter.Key(), cv)
+	}
+	return merged
+}
+
+func indirectInterface(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	return v
+}
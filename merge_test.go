@@ -0,0 +1,67 @@
+package ginx
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMergeStrategy(t *testing.T) {
+	var (
+		owner  = NewKey[string]("test.owner")
+		roles  = NewKey[[]string]("test.roles", WithMerge(MergeAppend))
+		scopes = NewKey[[]string]("test.scopes", WithMerge(MergeUnion))
+		labels = NewKey[map[string]any]("test.labels", WithMerge(MergeDeep))
+		doc    = NewKey[string]("test.doc", WithMerge(MergeRemove))
+	)
+
+	server := New()
+	root := server.RouterGroup()
+	api := root.MGroup("api", M{
+		owner.V("api"),
+		roles.V([]string{"admin"}),
+		scopes.V([]string{"read"}),
+		labels.V(map[string]any{"env": "prod", "team": map[string]any{"name": "a", "size": 1}}),
+		doc.V("api group"),
+	})
+	user := api.MGroup("user", M{roles.V([]string{"user"}), scopes.V([]string{"read", "write"})})
+	user.MGET("info", M{
+		owner.Unset(),
+		roles.V([]string{"guest"}),
+		labels.V(map[string]any{"team": map[string]any{"name": "b"}}),
+	})
+
+	check := func() {
+		var meta MetaData
+		root.Walk(func(info RouteInfo) {
+			if info.FullPath == "/api/user/info" {
+				meta = info.Meta
+			}
+		})
+
+		_, ok := owner.Get(meta)
+		assert.False(t, ok)
+		assert.Equal(t, []string{"admin", "user", "guest"}, roles.ShouldGet(meta))
+		assert.Equal(t, []string{"read", "write"}, scopes.ShouldGet(meta))
+		assert.Equal(t, map[string]any{"env": "prod", "team": map[string]any{"name": "b", "size": 1}}, labels.ShouldGet(meta))
+		_, ok = doc.Get(meta)
+		assert.False(t, ok)
+	}
+
+	// resolved on the fly
+	check()
+
+	// resolved at freeze time
+	assert.Nil(t, server.validateMeta())
+	server.resolveMeta()
+	server.metadata.Frozen()
+	check()
+}
+
+func TestMergeStrategyCheck(t *testing.T) {
+	assert.Panics(t, func() {
+		NewKey[string]("test.invalid", WithMerge(MergeAppend))
+	})
+	assert.Panics(t, func() {
+		NewKey[[]string]("test.deep", WithMerge(MergeDeep))
+	})
+}
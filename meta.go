@@ -1,8 +1,10 @@
 package ginx
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/ginx-contribs/ginx/pkg/resp"
 	"strings"
 	"time"
 )
@@ -21,7 +23,17 @@ func (m M) build() MetaData {
 	return metadata
 }
 
-// V is basic item in metadata
+func (m M) has(key string) bool {
+	for _, v := range m {
+		if v.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// V is basic item in metadata, the type-switch accessors return zero value if type does not match,
+// it is recommended to declare a typed Key by NewKey, then build and read metadata by the key.
 type V struct {
 	Key string
 	Val any
@@ -164,14 +176,6 @@ func (m MetaData) set(k string, v any) {
 	m.m[k] = v
 }
 
-func (m MetaData) applyM(meta MetaData) {
-	for k, v := range meta.m {
-		if !m.Has(k) {
-			m.set(k, v)
-		}
-	}
-}
-
 func (m MetaData) ShouldGet(key string) V {
 	val, _ := m.Get(key)
 	return val
@@ -198,6 +202,15 @@ func (m MetaData) Contains(v V) bool {
 	return get == v
 }
 
+// Range calls fn for each key and value, it stops if fn returns false
+func (m MetaData) Range(fn func(key string, val any) bool) {
+	for k, v := range m.m {
+		if !fn(k, v) {
+			return
+		}
+	}
+}
+
 func (m MetaData) String() string {
 	var buf strings.Builder
 	buf.WriteString("{")
@@ -213,41 +226,121 @@ func (m MetaData) String() string {
 	return buf.String()
 }
 
+// MarshalJSON encodes metadata as an object, the values which can not be encoded are formatted as strings
+func (m MetaData) MarshalJSON() ([]byte, error) {
+	values := make(map[string]json.RawMessage, len(m.m))
+	for k, v := range m.m {
+		data, err := json.Marshal(v)
+		if err != nil {
+			data, _ = json.Marshal(fmt.Sprint(v))
+		}
+		values[k] = data
+	}
+	return json.Marshal(values)
+}
+
 var emptyRouteMeta = routeMeta{MetaData: emptyMetaData}
 
 type routeMeta struct {
+	// metadata declared by route itself
 	MetaData MetaData
 	FullPath string
 	Method   string
 	// group
 	Group *RouterGroup
+
+	// effective metadata which has inherited from groups, it is resolved before metadata frozen.
+	effective MetaData
 }
 
 const _MetaKey = "github.com/246859/ginx.metadata"
 
-// metaDataHandler get metadata for each route from the global metadata, then store in the context
-func metaDataHandler(metadata *FrozenMap[string, routeMeta]) gin.HandlerFunc {
+// metaTable is an immutable table of effective route metadata, indexed by method and full path,
+// looking up the table does not need to build string key.
+type metaTable map[string]map[string]MetaData
+
+func (t metaTable) lookup(method, fullPath string) (MetaData, bool) {
+	meta, ok := t[method][fullPath]
+	return meta, ok
+}
+
+// compileMetaTable builds table from the resolved route metadata, it should be called after metadata resolved.
+func compileMetaTable(metadata *FrozenMap[string, routeMeta]) metaTable {
+	table := make(metaTable)
+	metadata.Range(func(_ string, meta routeMeta) {
+		// skip group meta
+		if meta.Method == "" {
+			return
+		}
+		routes, ok := table[meta.Method]
+		if !ok {
+			routes = make(map[string]MetaData)
+			table[meta.Method] = routes
+		}
+		routes[meta.FullPath] = meta.effective
+	})
+	return table
+}
+
+// metaDataHandler get effective metadata for each route from the server, then store in the context
+func metaDataHandler(s *Server) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		key := routeKey(ctx.Request.Method, ctx.FullPath())
-		src, e := metadata.Get(key)
-		if e {
-			ctx.Set(_MetaKey+key, src.MetaData)
+		var (
+			meta MetaData
+			ok   bool
+		)
+		if table := s.metaTable.Load(); table != nil {
+			meta, ok = table.lookup(ctx.Request.Method, ctx.FullPath())
+		} else if src, e := s.metadata.Get(routeKey(ctx.Request.Method, ctx.FullPath())); e {
+			// server is not running, resolve on the fly
+			meta, ok = s.inheritMeta(src).MetaData, true
+		}
+		if ok {
+			ctx.Set(_MetaKey, meta)
+			if envelope, ok := MetaEnvelope.Get(meta); ok {
+				resp.UseEnvelope(ctx, envelope)
+			}
 		}
 	}
 }
 
 var emptyMetaData = MetaData{m: map[string]any{}}
 
-// MetaFromCtx get metadata of route itself from context
+// MetaFromCtx get effective metadata of the current route from context, which includes the metadata inherited from groups.
+// It reads context keys without lock, so do not call it in other goroutines, use the copy of context instead.
 func MetaFromCtx(ctx *gin.Context) MetaData {
-	routeKey := routeKey(ctx.Request.Method, ctx.FullPath())
-	metadata, exists := ctx.Get(_MetaKey + routeKey)
-	if !exists {
-		return emptyMetaData
+	if meta, ok := ctx.Keys[_MetaKey].(MetaData); ok {
+		return meta
 	}
-	return metadata.(MetaData)
+	return emptyMetaData
 }
 
 func routeKey(method, path string) string {
 	return method + ":" + path
 }
+
+// inheritMeta returns meta info with effective metadata, after metadata frozen, it returns the resolved result directly.
+func (s *Server) inheritMeta(meta routeMeta) routeMeta {
+	if s.metadata.IsFrozen() {
+		meta.MetaData = meta.effective
+		return meta
+	}
+	parent := emptyMetaData
+	if meta.Group != nil {
+		parent = meta.Group.getMeta().MetaData
+	}
+	meta.MetaData = mergeMeta(parent, meta.MetaData)
+	return meta
+}
+
+// resolveMeta resolves effective metadata for all groups and routes, it should be called before metadata frozen.
+func (s *Server) resolveMeta() {
+	resolved := make(map[string]routeMeta)
+	s.metadata.Range(func(key string, meta routeMeta) {
+		meta.effective = s.inheritMeta(meta).MetaData
+		resolved[key] = meta
+	})
+	for key, meta := range resolved {
+		s.metadata.Set(key, meta)
+	}
+}
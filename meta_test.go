@@ -0,0 +1,93 @@
+package ginx
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/ginx-contribs/ginx/pkg/resp"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+var testRoleKey = NewKey[string]("test.role")
+
+func metaServer(handler gin.HandlerFunc) *Server {
+	server := New()
+	root := server.RouterGroup()
+	user := root.MGroup("user", M{testRoleKey.V("user")})
+	user.MGET("info", nil, handler)
+	return server
+}
+
+func TestMetaFromCtx(t *testing.T) {
+	var role string
+	server := metaServer(func(ctx *gin.Context) {
+		role, _ = testRoleKey.FromCtx(ctx)
+	})
+
+	serve := func() {
+		role = ""
+		recorder := httptest.NewRecorder()
+		server.Engine().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/user/info", nil))
+		assert.Equal(t, "user", role)
+	}
+
+	// before frozen
+	serve()
+
+	// after frozen
+	assert.Nil(t, server.freeze())
+	serve()
+}
+
+func TestMetaFromCtxAllocs(t *testing.T) {
+	ctx := &gin.Context{Keys: map[string]any{_MetaKey: M{testRoleKey.V("user")}.build()}}
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = MetaFromCtx(ctx)
+	})
+	assert.Zero(t, allocs)
+}
+
+func BenchmarkMetaFromCtx(b *testing.B) {
+	ctx := &gin.Context{Keys: map[string]any{_MetaKey: M{testRoleKey.V("user")}.build()}}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = testRoleKey.FromCtx(ctx)
+	}
+}
+
+func BenchmarkMetaDataHandler(b *testing.B) {
+	server := metaServer(func(ctx *gin.Context) {
+		_, _ = testRoleKey.FromCtx(ctx)
+	})
+	if err := server.freeze(); err != nil {
+		b.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/user/info", nil)
+	recorder := httptest.NewRecorder()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		server.Engine().ServeHTTP(recorder, req)
+	}
+}
+
+func TestMetaEnvelope(t *testing.T) {
+	server := New()
+	api := server.RouterGroup().MGroup("/api", M{MetaEnvelope.V(resp.ProblemEnvelope{})})
+	api.GET("/fail", func(ctx *gin.Context) {
+		resp.Fail(ctx).Error(errors.New("bad")).JSON()
+	})
+	server.RouterGroup().GET("/fail", func(ctx *gin.Context) {
+		resp.Fail(ctx).Error(errors.New("bad")).JSON()
+	})
+
+	for path, contentType := range map[string]string{"/api/fail": "application/problem+json", "/fail": "application/json; charset=utf-8"} {
+		recorder := httptest.NewRecorder()
+		server.Engine().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.Equal(t, contentType, recorder.Header().Get("Content-Type"), path)
+	}
+}
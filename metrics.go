@@ -0,0 +1,66 @@
+package ginx
+
+import (
+	"github.com/ginx-contribs/ginx/pkg/metrics"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// serverMetrics collects server level metrics
+type serverMetrics struct {
+	registry *metrics.Registry
+
+	connections     *metrics.Counter
+	openConnections *metrics.Gauge
+	hookDuration    *metrics.Histogram
+
+	// last state of each open connection
+	conns sync.Map
+}
+
+func newServerMetrics(registry *metrics.Registry) *serverMetrics {
+	if registry == nil {
+		return nil
+	}
+	return &serverMetrics{
+		registry:        registry,
+		connections:     registry.Counter("ginx_http_connections_total", "Total number of accepted connections."),
+		openConnections: registry.Gauge("ginx_http_open_connections", "Number of open connections by state.", "state"),
+		hookDuration:    registry.Histogram("ginx_hook_duration_seconds", "Duration of lifecycle hooks in seconds.", nil, "phase", "hook", "status"),
+	}
+}
+
+// connState returns a http.Server.ConnState callback which tracks connections, and calls next if it is not nil.
+func (m *serverMetrics) connState(next func(net.Conn, http.ConnState)) func(net.Conn, http.ConnState) {
+	return func(conn net.Conn, state http.ConnState) {
+		if prev, ok := m.conns.Load(conn); ok {
+			m.openConnections.Dec(prev.(http.ConnState).String())
+		}
+		switch state {
+		case http.StateNew:
+			m.connections.Inc()
+			fallthrough
+		case http.StateActive, http.StateIdle:
+			m.conns.Store(conn, state)
+			m.openConnections.Inc(state.String())
+		case http.StateHijacked, http.StateClosed:
+			m.conns.Delete(conn)
+		}
+		if next != nil {
+			next(conn, state)
+		}
+	}
+}
+
+func (m *serverMetrics) observeHook(phase, name string, err error, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	status := "done"
+	if err != nil {
+		status = "failed"
+	}
+	m.hookDuration.Observe(duration.Seconds(), phase, name, status)
+}
@@ -3,6 +3,10 @@ package ginx
 import (
 	"context"
 	"github.com/gin-gonic/gin"
+	"github.com/ginx-contribs/ginx/pkg/config"
+	"github.com/ginx-contribs/ginx/pkg/health"
+	"github.com/ginx-contribs/ginx/pkg/metrics"
+	"net"
 	"net/http"
 	"os"
 	"time"
@@ -18,14 +22,65 @@ type TLSOptions struct {
 	Key string `mapstructure:"key"`
 	// TLS Certificate file
 	Cert string `mapstructure:"cert"`
+	// additional certificates, they are selected by SNI, the Cert is used as default
+	Certificates []CertOptions `mapstructure:"certificates"`
+
+	// min tls version, such as 1.2 or TLS1.3, default is 1.2
+	MinVersion string `mapstructure:"minVersion"`
+	// max tls version, default is the max version supported
+	MaxVersion string `mapstructure:"maxVersion"`
+	// cipher suite names, such as TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, it is not configurable in TLS 1.3
+	CipherSuites []string `mapstructure:"cipherSuites"`
+	// curve names in preference order, such as X25519, P256
+	CurvePreferences []string `mapstructure:"curvePreferences"`
+	// ALPN protocols, such as h2, http/1.1
+	NextProtos []string `mapstructure:"nextProtos"`
+
+	// PEM encoded CA bundle files used to verify client certificates
+	ClientCAs []string `mapstructure:"clientCAs"`
+	// client authentication mode, one of none, request, require, verify-if-given, require-and-verify,
+	// default is require-and-verify if ClientCAs is not empty, otherwise none.
+	ClientAuth string `mapstructure:"clientAuth"`
+
+	// interval to check whether certificate files are modified, default is 1 minute, negative means never reload
+	ReloadInterval time.Duration `mapstructure:"reloadInterval"`
+	// certificate expiry hook will be called if any certificate expires within this period, default is 30 days
+	ExpiryWarning time.Duration `mapstructure:"expiryWarning"`
+}
+
+// CertOptions is a certificate key pair
+type CertOptions struct {
+	// TLS Key file
+	Key string `mapstructure:"key"`
+	// TLS Certificate file
+	Cert string `mapstructure:"cert"`
+}
+
+// HTTP2Options configures the HTTP/2 server, zero values mean the defaults of golang.org/x/net/http2
+type HTTP2Options struct {
+	// serve HTTP/2 over cleartext TCP, both prior knowledge and upgrade from HTTP/1.1 are supported
+	H2C bool `mapstructure:"h2c"`
+	// max concurrent streams per connection, default is 250
+	MaxConcurrentStreams uint32 `mapstructure:"maxConcurrentStreams"`
+	// max frame size the server is willing to read, valid range is 16KB to 16MB, default is 1MB
+	MaxReadFrameSize uint32 `mapstructure:"maxReadFrameSize"`
+	// idle connections will be closed after this duration, default is IdleTimeout of Options
+	IdleTimeout time.Duration `mapstructure:"idleTimeout"`
+	// flow control window size of each connection, default is 1MB
+	MaxUploadBufferPerConnection int32 `mapstructure:"maxUploadBufferPerConnection"`
+	// flow control window size of each stream, default is 1MB
+	MaxUploadBufferPerStream int32 `mapstructure:"maxUploadBufferPerStream"`
 }
 
 type Options struct {
 	Mode string `mapstructure:"mode"`
 	// specifies the TCP address for the server to listen on,
-	// in the form "host:port". If empty, ":http" (port 80) is used
+	// in the form "host:port". If both Address and Listeners are empty, ":8080" is used
 	Address string `mapstructure:"address"`
 
+	// additional addresses to listen on, the server serves on all of them with Address at the same time
+	Listeners []ListenerOptions `mapstructure:"listeners"`
+
 	// ReadTimeout is the maximum duration for reading the entire
 	// request, including the body. A zero or negative value means
 	// there will be no timeout.
@@ -45,7 +100,7 @@ type Options struct {
 
 	// MaxMultipartMemory value of 'maxMemory' param that is given to http.Request's ParseMultipartForm
 	// method call.
-	MaxMultipartMemory int64 `mapstructure:"xaxMultipartMemory"`
+	MaxMultipartMemory int64 `mapstructure:"maxMultipartMemory"`
 
 	// MaxHeaderBytes controls the maximum number of bytes the
 	// server will read parsing the request header's keys and
@@ -55,8 +110,18 @@ type Options struct {
 	// simple TLS config
 	TLS *TLSOptions `mapstructure:"tls"`
 
+	// HTTP/2 server options, HTTP/2 is only available over TLS if it is nil
+	HTTP2 *HTTP2Options `mapstructure:"http2"`
+
+	// admin server options, admin server is disabled if it is nil
+	Admin *AdminOptions `mapstructure:"admin"`
+
 	// max wait time after server shutdown
 	MaxShutdownTimeout time.Duration `mapstructure:"maxShutdownTimeout"`
+
+	// wait time between receiving stop signal and shutting down, readiness is turned off during it,
+	// so that load balancers could stop sending requests in time.
+	PreStopDelay time.Duration `mapstructure:"preStopDelay"`
 }
 
 // WithCtx apply the server root context
@@ -90,6 +155,31 @@ func WithStopSignals(signals ...os.Signal) Option {
 	}
 }
 
+// WithGracefulRestart enables graceful restart, when receives the signal, the server starts a new process of the same
+// executable and passes the listeners to it, after the new process is ready, the server shutdowns gracefully.
+// Timeout is the max wait time for the new process to be ready, the signal should not be one of the stop signals.
+func WithGracefulRestart(signal os.Signal, timeout time.Duration) Option {
+	return func(server *Server) {
+		server.restartSignal = signal
+		server.restartTimeout = timeout
+	}
+}
+
+// WithConfig sets the config source of server, it enables Reload and Watch. If server options are loaded
+// from the same source by LoadOptions, the key should be set by config.WithKey so that changes of them could be found.
+func WithConfig(opts ...config.Option) Option {
+	return func(server *Server) {
+		server.configOpts = opts
+	}
+}
+
+// WithReloadSignal reloads config when receives the signal, such as syscall.SIGHUP
+func WithReloadSignal(signal os.Signal) Option {
+	return func(server *Server) {
+		server.reloadSignal = signal
+	}
+}
+
 // WithEngine apply a custom engine
 func WithEngine(engine *gin.Engine) Option {
 	return func(server *Server) {
@@ -135,6 +225,21 @@ func WithAddress(address string) Option {
 	}
 }
 
+// WithListen listens on an additional address, network could be tcp or unix,
+// mode is the file mode of unix socket, it is ignored for tcp.
+func WithListen(network, address string, mode os.FileMode) Option {
+	return func(server *Server) {
+		server.options.Listeners = append(server.options.Listeners, ListenerOptions{Network: network, Address: address, FileMode: mode})
+	}
+}
+
+// WithListener serves on the given listeners, they will be closed when server shutdown
+func WithListener(listeners ...net.Listener) Option {
+	return func(server *Server) {
+		server.listeners = append(server.listeners, listeners...)
+	}
+}
+
 func WithReadTimeout(timeout time.Duration) Option {
 	return func(server *Server) {
 		server.options.ReadTimeout = timeout
@@ -176,6 +281,29 @@ func WithMaxShutdownWait(timeout time.Duration) Option {
 	}
 }
 
+// WithPreStopDelay waits the delay after receiving stop signal before shutting down, see Options.PreStopDelay
+func WithPreStopDelay(delay time.Duration) Option {
+	return func(server *Server) {
+		server.options.PreStopDelay = delay
+	}
+}
+
+// WithHealth sets the health registry, its readiness follows the server lifecycle,
+// and it is served by the liveness and readiness endpoints of admin server.
+func WithHealth(registry *health.Registry) Option {
+	return func(server *Server) {
+		server.health = registry
+	}
+}
+
+// WithMetrics collects server level metrics into the registry, including connections and hook durations,
+// and the registry is served by the metrics endpoint of admin server.
+func WithMetrics(registry *metrics.Registry) Option {
+	return func(server *Server) {
+		server.metrics = newServerMetrics(registry)
+	}
+}
+
 func WithMode(mode string) Option {
 	return func(server *Server) {
 		server.options.Mode = mode
@@ -187,3 +315,41 @@ func WithTLS(key string, cert string) Option {
 		server.options.TLS = &TLSOptions{Key: key, Cert: cert}
 	}
 }
+
+// WithTLSOptions apply the whole tls options
+func WithTLSOptions(options TLSOptions) Option {
+	return func(server *Server) {
+		server.options.TLS = &options
+	}
+}
+
+// WithHTTP2 apply the whole http2 options
+func WithHTTP2(options HTTP2Options) Option {
+	return func(server *Server) {
+		server.options.HTTP2 = &options
+	}
+}
+
+// WithH2C enables HTTP/2 over cleartext TCP
+func WithH2C() Option {
+	return func(server *Server) {
+		if server.options.HTTP2 == nil {
+			server.options.HTTP2 = &HTTP2Options{}
+		}
+		server.options.HTTP2.H2C = true
+	}
+}
+
+// WithAdmin serves admin endpoints on the address, see AdminOptions
+func WithAdmin(address string) Option {
+	return func(server *Server) {
+		server.options.Admin = &AdminOptions{Address: address}
+	}
+}
+
+// WithCertExpiry registers hook which will be called periodically if any certificate is going to expire
+func WithCertExpiry(hook CertExpiryFn) Option {
+	return func(server *Server) {
+		server.certExpiry = hook
+	}
+}
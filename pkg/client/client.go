@@ -0,0 +1,117 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ginx-contribs/ginx/constant/headers"
+	"github.com/ginx-contribs/ginx/constant/mimes"
+	"github.com/ginx-contribs/ginx/constant/status"
+	"github.com/ginx-contribs/ginx/pkg/resp"
+	"github.com/ginx-contribs/ginx/pkg/resp/statuserr"
+	"io"
+	"mime"
+	"net/http"
+)
+
+var ErrUnsupportedContentType = errors.New("unsupported content type")
+
+// Envelope is the standard envelope of ginx response with typed data, see resp.Body
+type Envelope[T any] struct {
+	Code  int    `json:"code"`
+	Data  T      `json:"data"`
+	Msg   string `json:"msg"`
+	Error string `json:"error"`
+}
+
+// Do sends the request by client, and decodes the response, http.DefaultClient is used if client is nil.
+func Do[T any](client *http.Client, req *http.Request) (T, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	defer res.Body.Close()
+	return Decode[T](res)
+}
+
+// Decode decodes the data of standard envelope into T. For error responses, which status code is
+// greater than or equal to 400, it returns statuserr.Error reconstructed from the envelope or problem details,
+// including status, custom code, type, field errors and extensions.
+// The body is not closed.
+func Decode[T any](res *http.Response) (T, error) {
+	var zero T
+	contentType, _, _ := mime.ParseMediaType(res.Header.Get(headers.ContentType))
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return zero, err
+	}
+
+	if contentType == mimes.ApplicationProblemJSON {
+		var problem resp.Problem
+		if err := json.Unmarshal(data, &problem); err != nil {
+			return zero, err
+		}
+		return zero, problemError(res.StatusCode, problem, data)
+	}
+
+	if contentType != mimes.ApplicationJSON {
+		if res.StatusCode >= 400 {
+			return zero, statuserr.Status(status.Status(res.StatusCode)).SetError(errors.New(http.StatusText(res.StatusCode)))
+		}
+		return zero, fmt.Errorf("%w: %q", ErrUnsupportedContentType, contentType)
+	}
+
+	var envelope Envelope[T]
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		if res.StatusCode >= 400 {
+			return zero, statuserr.Status(status.Status(res.StatusCode)).SetError(errors.New(http.StatusText(res.StatusCode)))
+		}
+		return zero, err
+	}
+
+	if res.StatusCode >= 400 {
+		msg := envelope.Error
+		if msg == "" {
+			msg = http.StatusText(res.StatusCode)
+		}
+		statusErr := statuserr.Status(status.Status(res.StatusCode)).SetError(errors.New(msg))
+		if envelope.Code != res.StatusCode {
+			statusErr.Code = envelope.Code
+		}
+		return zero, statusErr
+	}
+	return envelope.Data, nil
+}
+
+func problemError(code int, problem resp.Problem, data []byte) error {
+	msg := problem.Detail
+	if msg == "" {
+		msg = problem.Title
+	}
+	if problem.Status != 0 {
+		code = problem.Status
+	}
+	statusErr := statuserr.Status(status.Status(code)).SetError(errors.New(msg)).SetCode(problem.Code).SetFields(problem.Errors...)
+	if problem.Type != "about:blank" {
+		statusErr.Type = problem.Type
+	}
+
+	// the members which are not defined by Problem are extensions
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err == nil {
+		for _, name := range []string{"type", "title", "status", "detail", "instance", "code", "errors"} {
+			delete(members, name)
+		}
+		for name, raw := range members {
+			var v any
+			_ = json.Unmarshal(raw, &v)
+			statusErr = statusErr.SetExtension(name, v)
+		}
+	}
+	return statusErr
+}
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/ginx-contribs/ginx/constant/status"
+	"github.com/ginx-contribs/ginx/pkg/resp"
+	"github.com/ginx-contribs/ginx/pkg/resp/statuserr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type user struct {
+	Name string `json:"name"`
+}
+
+func TestDecode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/user", func(ctx *gin.Context) {
+		resp.Of[user](ctx).Data(user{Name: "jack"}).JSON()
+	})
+	engine.GET("/missing", func(ctx *gin.Context) {
+		resp.Fail(ctx).Error(statuserr.Errorf("user not found").SetStatus(status.NotFound).SetCode(1004)).JSON()
+	})
+	engine.GET("/problem", func(ctx *gin.Context) {
+		resp.UseEnvelope(ctx, resp.ProblemEnvelope{})
+		resp.Fail(ctx).Error(statuserr.BadRequest(errors.New("name is required")).
+			SetFields(statuserr.FieldError{Field: "name", Detail: "required"}).
+			SetExtension("hint", "retry")).JSON()
+	})
+	server := httptest.NewServer(engine)
+	defer server.Close()
+
+	get := func(path string) (user, error) {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		return Do[user](server.Client(), req)
+	}
+
+	u, err := get("/user")
+	require.NoError(t, err)
+	assert.Equal(t, "jack", u.Name)
+
+	_, err = get("/missing")
+	var statusErr statuserr.Error
+	require.True(t, errors.As(err, &statusErr))
+	assert.Equal(t, status.NotFound, statusErr.Status)
+	assert.Equal(t, 1004, statusErr.Code)
+	assert.Equal(t, "user not found", statusErr.Error())
+
+	_, err = get("/problem")
+	require.True(t, errors.As(err, &statusErr))
+	assert.Equal(t, status.BadRequest, statusErr.Status)
+	assert.Zero(t, statusErr.Code)
+	assert.Equal(t, "name is required", statusErr.Error())
+	assert.Equal(t, []statuserr.FieldError{{Field: "name", Detail: "required"}}, statusErr.Fields())
+	assert.Equal(t, map[string]any{"hint": "retry"}, statusErr.Extensions())
+}
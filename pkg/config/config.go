@@ -0,0 +1,335 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/dstgo/size"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	ErrUnknownKeys   = errors.New("unknown config keys")
+	ErrUnknownFormat = errors.New("unknown config format")
+)
+
+// Validator will be called after decoding if the target implements it
+type Validator interface {
+	Validate() error
+}
+
+type Options struct {
+	// config files, the latter overrides the former, format is decided by extension: .yaml, .yml, .toml, .json
+	Files []string
+	// prefix of environment variables, environment variables are ignored if it is empty
+	EnvPrefix string
+	// dot separated path of the sub-tree to decode, such as server or middlewares.cors
+	Key string
+	// do not report unknown keys
+	AllowUnknown bool
+	// additional decode hooks, they are executed before the builtin ones
+	DecodeHooks []mapstructure.DecodeHookFunc
+}
+
+type Option func(options *Options)
+
+func WithFiles(files ...string) Option {
+	return func(options *Options) {
+		options.Files = append(options.Files, files...)
+	}
+}
+
+// WithEnv overlays environment variables with the prefix, variable names are the upper snake case of key path,
+// for example, GINX_TLS_MIN_VERSION for tls.minVersion with prefix GINX. Slices are separated by comma.
+func WithEnv(prefix string) Option {
+	return func(options *Options) {
+		options.EnvPrefix = prefix
+	}
+}
+
+func WithKey(key string) Option {
+	return func(options *Options) {
+		options.Key = key
+	}
+}
+
+func WithAllowUnknown() Option {
+	return func(options *Options) {
+		options.AllowUnknown = true
+	}
+}
+
+func WithDecodeHooks(hooks ...mapstructure.DecodeHookFunc) Option {
+	return func(options *Options) {
+		options.DecodeHooks = append(options.DecodeHooks, hooks...)
+	}
+}
+
+// Load reads config files and environment variables, then decodes them into target by mapstructure tags.
+// Durations are parsed like 10s, sizes like 10MB, file modes like 0660.
+func Load(target any, opts ...Option) error {
+	snapshot, err := Read(opts...)
+	if err != nil {
+		return err
+	}
+	return snapshot.Decode(snapshot.options.Key, target)
+}
+
+// Snapshot is the raw data read from config files at a moment, it could be decoded into different targets.
+type Snapshot struct {
+	options Options
+	data    map[string]any
+}
+
+// Read reads and merges the config files
+func Read(opts ...Option) (*Snapshot, error) {
+	var options Options
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	data := make(map[string]any)
+	for _, file := range options.Files {
+		fileData, err := ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		merge(data, fileData)
+	}
+	return &Snapshot{options: options, data: data}, nil
+}
+
+// Has reports whether the dot separated key exists in config files
+func (s *Snapshot) Has(key string) bool {
+	return lookup(s.data, splitKey(key)) != nil
+}
+
+// Decode decodes the sub-tree of dot separated key into target, the environment variables are overlaid,
+// the key of options is ignored, empty key means the whole tree.
+func (s *Snapshot) Decode(key string, target any) error {
+	path := splitKey(key)
+	sub, _ := lookup(s.data, path).(map[string]any)
+	// do not modify the snapshot when overlaying environment variables
+	sub = clone(sub)
+
+	if s.options.EnvPrefix != "" {
+		overlayEnv(sub, reflect.TypeOf(target), append([]string{s.options.EnvPrefix}, path...), os.LookupEnv)
+	}
+
+	return Decode(sub, target, func(options *Options) {
+		*options = s.options
+	})
+}
+
+// Decode decodes the raw data into target with the decode hooks used by Load, then validates it
+func Decode(data map[string]any, target any, opts ...Option) error {
+	var options Options
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	var metadata mapstructure.Metadata
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(append(options.DecodeHooks,
+			mapstructure.StringToTimeDurationHookFunc(),
+			fileModeHookFunc,
+			sizeHookFunc,
+			mapstructure.StringToSliceHookFunc(","),
+		)...),
+		Metadata:         &metadata,
+		WeaklyTypedInput: true,
+		Result:           target,
+	})
+	if err != nil {
+		return err
+	}
+	if err := decoder.Decode(data); err != nil {
+		return err
+	}
+
+	if len(metadata.Unused) > 0 && !options.AllowUnknown {
+		slices.Sort(metadata.Unused)
+		return fmt.Errorf("%w: %s", ErrUnknownKeys, strings.Join(metadata.Unused, ", "))
+	}
+
+	if validator, ok := target.(Validator); ok {
+		return validator.Validate()
+	}
+	return nil
+}
+
+// ReadFile reads raw config data from file
+func ReadFile(file string) (map[string]any, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make(map[string]any)
+	switch ext := strings.ToLower(filepath.Ext(file)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &data)
+	case ".toml":
+		err = toml.Unmarshal(content, &data)
+	case ".json":
+		err = json.Unmarshal(content, &data)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownFormat, file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", file, err)
+	}
+	return data, nil
+}
+
+// merge merges src into dst recursively
+func merge(dst, src map[string]any) {
+	for key, value := range src {
+		srcMap, ok := value.(map[string]any)
+		dstMap, exists := dst[key].(map[string]any)
+		if ok && exists {
+			merge(dstMap, srcMap)
+		} else {
+			dst[key] = value
+		}
+	}
+}
+
+func splitKey(key string) []string {
+	if key == "" {
+		return nil
+	}
+	return strings.Split(key, ".")
+}
+
+// clone copies the map and nested maps, returns an empty map if m is nil
+func clone(m map[string]any) map[string]any {
+	c := make(map[string]any, len(m))
+	for key, value := range m {
+		if sub, ok := value.(map[string]any); ok {
+			value = clone(sub)
+		}
+		c[key] = value
+	}
+	return c
+}
+
+func lookup(data map[string]any, path []string) any {
+	var value any = data
+	for _, key := range path {
+		m, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = m[key]
+	}
+	return value
+}
+
+// overlayEnv walks the fields of struct type, and sets the value of environment variable into data if it exists
+func overlayEnv(data map[string]any, typ reflect.Type, envPath []string, lookupEnv func(string) (string, bool)) {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return
+	}
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		switch {
+		case opts == "squash":
+			overlayEnv(data, fieldType, envPath, lookupEnv)
+		case fieldType.Kind() == reflect.Struct:
+			sub, ok := data[name].(map[string]any)
+			if !ok {
+				sub = make(map[string]any)
+			}
+			overlayEnv(sub, fieldType, append(envPath, name), lookupEnv)
+			if len(sub) > 0 {
+				data[name] = sub
+			}
+		case fieldType.Kind() == reflect.Func, fieldType.Kind() == reflect.Interface, fieldType.Kind() == reflect.Chan:
+		case fieldType.Kind() == reflect.Slice && fieldType.Elem().Kind() == reflect.Struct:
+			// slices of struct could only be configured in files
+		default:
+			if value, ok := lookupEnv(envName(append(envPath, name))); ok {
+				data[name] = value
+			}
+		}
+	}
+}
+
+// envName returns upper snake case name of the path, such as GINX_TLS_MIN_VERSION for [GINX tls minVersion]
+func envName(path []string) string {
+	var sb strings.Builder
+	for i, name := range path {
+		if i > 0 {
+			sb.WriteByte('_')
+		}
+		var prev rune
+		for _, r := range name {
+			if unicode.IsUpper(r) && (unicode.IsLower(prev) || unicode.IsDigit(prev)) {
+				sb.WriteByte('_')
+			}
+			sb.WriteRune(unicode.ToUpper(r))
+			prev = r
+		}
+	}
+	return sb.String()
+}
+
+// sizeHookFunc parses size string like 10MB into bytes for integer fields
+func sizeHookFunc(from reflect.Type, to reflect.Type, data any) (any, error) {
+	if from.Kind() != reflect.String {
+		return data, nil
+	}
+	switch to.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+	default:
+		return data, nil
+	}
+	s, ok := size.LookupTo(strings.ReplaceAll(data.(string), " ", ""), size.B)
+	if !ok {
+		// let the decoder handle it
+		return data, nil
+	}
+	return uint64(s.Data), nil
+}
+
+// fileModeHookFunc parses octal string like 0660 into os.FileMode
+func fileModeHookFunc(from reflect.Type, to reflect.Type, data any) (any, error) {
+	if from.Kind() != reflect.String || to != reflect.TypeOf(os.FileMode(0)) {
+		return data, nil
+	}
+	mode, err := strconv.ParseUint(data.(string), 8, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid file mode %q: %w", data, err)
+	}
+	return os.FileMode(mode), nil
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testConfig struct {
+	Name     string        `mapstructure:"name"`
+	Timeout  time.Duration `mapstructure:"timeout"`
+	MaxBytes int64         `mapstructure:"maxBytes"`
+	Mode     os.FileMode   `mapstructure:"mode"`
+	Tags     []string      `mapstructure:"tags"`
+	Nested   *struct {
+		Enabled bool `mapstructure:"enabled"`
+	} `mapstructure:"nested"`
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	yamlFile := filepath.Join(dir, "app.yaml")
+	tomlFile := filepath.Join(dir, "app.toml")
+	jsonFile := filepath.Join(dir, "app.json")
+	require.NoError(t, os.WriteFile(yamlFile, []byte("app:\n  name: yaml\n  timeout: 10s\n  maxBytes: 10MB\n  tags: [a, b]\n"), 0o600))
+	require.NoError(t, os.WriteFile(tomlFile, []byte("[app]\nmode = \"0660\"\n[app.nested]\nenabled = true\n"), 0o600))
+	require.NoError(t, os.WriteFile(jsonFile, []byte(`{"app": {"name": "json"}}`), 0o600))
+
+	t.Setenv("TEST_APP_TIMEOUT", "1m")
+	t.Setenv("TEST_APP_MAX_BYTES", "1KB")
+	t.Setenv("TEST_APP_TAGS", "x,y")
+
+	var cfg testConfig
+	require.NoError(t, Load(&cfg, WithFiles(yamlFile, tomlFile, jsonFile), WithEnv("TEST"), WithKey("app")))
+	assert.Equal(t, "json", cfg.Name)
+	assert.Equal(t, time.Minute, cfg.Timeout)
+	assert.Equal(t, int64(1024), cfg.MaxBytes)
+	assert.Equal(t, os.FileMode(0o660), cfg.Mode)
+	assert.Equal(t, []string{"x", "y"}, cfg.Tags)
+	require.NotNil(t, cfg.Nested)
+	assert.True(t, cfg.Nested.Enabled)
+
+	// unknown keys
+	require.NoError(t, os.WriteFile(jsonFile, []byte(`{"app": {"nmae": "json", "nested": {"enable": true}}}`), 0o600))
+	err := Load(&cfg, WithFiles(jsonFile), WithKey("app"))
+	assert.ErrorIs(t, err, ErrUnknownKeys)
+	assert.ErrorContains(t, err, "nested.enable, nmae")
+	assert.NoError(t, Load(&cfg, WithFiles(jsonFile), WithKey("app"), WithAllowUnknown()))
+
+	assert.ErrorIs(t, Load(&cfg, WithFiles(filepath.Join(dir, "app.ini"))), os.ErrNotExist)
+}
+
+func TestEnvName(t *testing.T) {
+	assert.Equal(t, "GINX_TLS_MIN_VERSION", envName([]string{"GINX", "tls", "minVersion"}))
+	assert.Equal(t, "GINX_HTTP2_MAX_READ_FRAME_SIZE", envName([]string{"GINX", "http2", "maxReadFrameSize"}))
+	assert.Equal(t, "GINX_SHOW_URL", envName([]string{"GINX", "showURL"}))
+}
@@ -0,0 +1,254 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/ginx-contribs/ginx/constant/headers"
+	"github.com/ginx-contribs/ginx/constant/mimes"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// status of check and report
+const (
+	StatusUp = "up"
+	// non-critical checks failed
+	StatusDegraded = "degraded"
+	StatusDown     = "down"
+)
+
+var ErrTimeout = errors.New("check timeout")
+
+// Checker checks whether a component is healthy
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc is an adapter to allow the use of ordinary functions as Checker
+type CheckerFunc func(ctx context.Context) error
+
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+type CheckOptions struct {
+	// max duration of each check, default is 5 seconds
+	Timeout time.Duration
+	// report is down if a critical check fails, otherwise degraded, default is true
+	Critical bool
+	// the result will be cached within the interval, zero means checking on every request
+	Interval time.Duration
+	// the check is also used for liveness, otherwise readiness only
+	Liveness bool
+}
+
+type CheckOption func(options *CheckOptions)
+
+func WithTimeout(timeout time.Duration) CheckOption {
+	return func(options *CheckOptions) {
+		options.Timeout = timeout
+	}
+}
+
+func WithCritical(critical bool) CheckOption {
+	return func(options *CheckOptions) {
+		options.Critical = critical
+	}
+}
+
+func WithInterval(interval time.Duration) CheckOption {
+	return func(options *CheckOptions) {
+		options.Interval = interval
+	}
+}
+
+func WithLiveness() CheckOption {
+	return func(options *CheckOptions) {
+		options.Liveness = true
+	}
+}
+
+// Result is the result of a check
+type Result struct {
+	Status    string        `json:"status"`
+	Critical  bool          `json:"critical"`
+	Error     string        `json:"error,omitempty"`
+	Duration  time.Duration `json:"duration"`
+	CheckedAt time.Time     `json:"checkedAt"`
+}
+
+// Report is the aggregated result of checks
+type Report struct {
+	Status string            `json:"status"`
+	Ready  bool              `json:"ready"`
+	Checks map[string]Result `json:"checks,omitempty"`
+}
+
+type check struct {
+	name    string
+	checker Checker
+	options CheckOptions
+
+	mu     sync.Mutex
+	cached *Result
+}
+
+func (c *check) run(ctx context.Context) Result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cached != nil && c.options.Interval > 0 && time.Since(c.cached.CheckedAt) < c.options.Interval {
+		return *c.cached
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.options.Timeout)
+	defer cancel()
+
+	begin := time.Now()
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- c.checker.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-errCh:
+	case <-ctx.Done():
+		err = ErrTimeout
+	}
+
+	result := Result{Status: StatusUp, Critical: c.options.Critical, Duration: time.Since(begin), CheckedAt: begin}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	c.cached = &result
+	return result
+}
+
+// Registry holds the registered checkers, and serves liveness and readiness reports.
+type Registry struct {
+	mu     sync.RWMutex
+	checks []*check
+	// readiness gate, it is turned off when server is going to shut down
+	ready atomic.Bool
+}
+
+// New returns an empty registry, it is ready by default
+func New() *Registry {
+	r := new(Registry)
+	r.ready.Store(true)
+	return r
+}
+
+// Register registers a named checker, the check with the same name will be replaced
+func (r *Registry) Register(name string, checker Checker, opts ...CheckOption) {
+	options := CheckOptions{Critical: true}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = time.Second * 5
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c := &check{name: name, checker: checker, options: options}
+	for i, exist := range r.checks {
+		if exist.name == name {
+			r.checks[i] = c
+			return
+		}
+	}
+	r.checks = append(r.checks, c)
+}
+
+// SetReady turns on or off the readiness gate
+func (r *Registry) SetReady(ready bool) {
+	r.ready.Store(ready)
+}
+
+// Ready reports whether the readiness gate is on
+func (r *Registry) Ready() bool {
+	return r.ready.Load()
+}
+
+// Liveness runs the liveness checks
+func (r *Registry) Liveness(ctx context.Context) Report {
+	report := r.run(ctx, true)
+	report.Ready = r.Ready()
+	return report
+}
+
+// Readiness runs all the checks, the report is down if readiness gate is off
+func (r *Registry) Readiness(ctx context.Context) Report {
+	report := r.run(ctx, false)
+	report.Ready = r.Ready()
+	if !report.Ready {
+		report.Status = StatusDown
+	}
+	return report
+}
+
+func (r *Registry) run(ctx context.Context, liveness bool) Report {
+	r.mu.RLock()
+	checks := make([]*check, 0, len(r.checks))
+	for _, c := range r.checks {
+		if !liveness || c.options.Liveness {
+			checks = append(checks, c)
+		}
+	}
+	r.mu.RUnlock()
+
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.run(ctx)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusUp, Checks: make(map[string]Result, len(checks))}
+	for i, c := range checks {
+		result := results[i]
+		report.Checks[c.name] = result
+		if result.Status == StatusUp {
+			continue
+		}
+		if result.Critical {
+			report.Status = StatusDown
+		} else if report.Status == StatusUp {
+			report.Status = StatusDegraded
+		}
+	}
+	return report
+}
+
+// LivenessHandler serves the liveness report, status code is 503 if it is down
+func (r *Registry) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		writeReport(w, r.Liveness(req.Context()))
+	})
+}
+
+// ReadinessHandler serves the readiness report, status code is 503 if it is down or not ready
+func (r *Registry) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		writeReport(w, r.Readiness(req.Context()))
+	})
+}
+
+func writeReport(w http.ResponseWriter, report Report) {
+	code := http.StatusOK
+	if report.Status == StatusDown {
+		code = http.StatusServiceUnavailable
+	}
+	w.Header().Set(headers.ContentType, mimes.ApplicationJSON)
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(report)
+}
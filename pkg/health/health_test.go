@@ -0,0 +1,71 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRegistry(t *testing.T) {
+	registry := New()
+
+	var calls atomic.Int32
+	registry.Register("db", CheckerFunc(func(ctx context.Context) error {
+		calls.Add(1)
+		return nil
+	}), WithLiveness(), WithInterval(time.Minute))
+	registry.Register("cache", CheckerFunc(func(ctx context.Context) error {
+		return errors.New("connection refused")
+	}), WithCritical(false))
+
+	report := registry.Readiness(context.Background())
+	assert.Equal(t, StatusDegraded, report.Status)
+	assert.Equal(t, StatusUp, report.Checks["db"].Status)
+	assert.Equal(t, "connection refused", report.Checks["cache"].Error)
+
+	// cached
+	report = registry.Liveness(context.Background())
+	assert.Equal(t, StatusUp, report.Status)
+	assert.Len(t, report.Checks, 1)
+	assert.Equal(t, int32(1), calls.Load())
+
+	registry.Register("queue", CheckerFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}), WithTimeout(time.Millisecond*10))
+	report = registry.Readiness(context.Background())
+	assert.Equal(t, StatusDown, report.Status)
+	assert.Equal(t, ErrTimeout.Error(), report.Checks["queue"].Error)
+}
+
+func TestHandler(t *testing.T) {
+	registry := New()
+	registry.Register("db", CheckerFunc(func(ctx context.Context) error { return nil }))
+
+	serve := func(handler http.Handler) (int, Report) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+		var report Report
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &report))
+		return recorder.Code, report
+	}
+
+	code, report := serve(registry.ReadinessHandler())
+	assert.Equal(t, http.StatusOK, code)
+	assert.True(t, report.Ready)
+
+	registry.SetReady(false)
+	code, report = serve(registry.ReadinessHandler())
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, StatusDown, report.Status)
+
+	code, _ = serve(registry.LivenessHandler())
+	assert.Equal(t, http.StatusOK, code)
+}
@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry()
+
+	requests := registry.Counter("requests_total", "Total requests.", "method", "path")
+	requests.Inc("GET", `/a"b`)
+	requests.Add(2, "GET", `/a"b`)
+	requests.Add(-1, "GET", `/a"b`)
+	// registered already
+	registry.Counter("requests_total", "Total requests.", "method", "path").Inc("POST", "/")
+
+	inflight := registry.Gauge("inflight", "")
+	inflight.Inc()
+	inflight.Inc()
+	inflight.Dec()
+
+	latency := registry.Histogram("latency_seconds", "Latency\nin seconds.", []float64{1, 0.1})
+	latency.Observe(0.05)
+	latency.Observe(0.1)
+	latency.Observe(3)
+
+	var buf bytes.Buffer
+	n, err := registry.WriteTo(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(buf.Len()), n)
+	assert.Equal(t, `# TYPE inflight gauge
+inflight 1
+# HELP latency_seconds Latency\nin seconds.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{le="0.1"} 2
+latency_seconds_bucket{le="1"} 2
+latency_seconds_bucket{le="+Inf"} 3
+latency_seconds_sum 3.15
+latency_seconds_count 3
+# HELP requests_total Total requests.
+# TYPE requests_total counter
+requests_total{method="GET",path="/a\"b"} 3
+requests_total{method="POST",path="/"} 1
+`, buf.String())
+
+	assert.Panics(t, func() {
+		registry.Gauge("requests_total", "")
+	})
+	assert.Panics(t, func() {
+		requests.Inc("GET")
+	})
+}
+
+func TestStatusClass(t *testing.T) {
+	assert.Equal(t, "2xx", StatusClass(204))
+	assert.Equal(t, "5xx", StatusClass(503))
+	assert.Equal(t, "0", StatusClass(0))
+}
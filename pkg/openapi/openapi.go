@@ -0,0 +1,397 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/ginx-contribs/ginx"
+	"github.com/ginx-contribs/ginx/constant/methods"
+	"github.com/ginx-contribs/ginx/constant/mimes"
+	"github.com/ginx-contribs/ginx/constant/status"
+	"github.com/ginx-contribs/ginx/pkg/resp"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// errorSchemaName is the component name of response envelope without data
+	errorSchemaName = "Error"
+	// problemSchemaName is the component name of problem details, see resp.Problem
+	problemSchemaName = "Problem"
+)
+
+// EnvelopeSchema returns the schema of response body wrapped by envelope, data is nil for error responses.
+type EnvelopeSchema func(data *Schema) *Schema
+
+// StandardEnvelope returns the schema of resp.Body, it is the schema of resp.StandardEnvelope
+func StandardEnvelope(data *Schema) *Schema {
+	schema := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"code":  {Type: "integer", Format: "int64"},
+			"msg":   {Type: "string"},
+			"error": {Type: "string"},
+		},
+	}
+	if data != nil {
+		schema.Properties["data"] = data
+	}
+	return schema
+}
+
+// ProblemSchema returns the schema of resp.Problem
+func ProblemSchema() *Schema {
+	return &Schema{
+		Type:     "object",
+		Required: []string{"type", "title", "status"},
+		Properties: map[string]*Schema{
+			"type":     {Type: "string", Format: "uri-reference"},
+			"title":    {Type: "string"},
+			"status":   {Type: "integer", Format: "int64"},
+			"detail":   {Type: "string"},
+			"instance": {Type: "string", Format: "uri-reference"},
+			"code":     {Type: "integer", Format: "int64"},
+			"errors": {
+				Type: "array",
+				Items: &Schema{
+					Type: "object",
+					Properties: map[string]*Schema{
+						"field":  {Type: "string"},
+						"detail": {Type: "string"},
+					},
+				},
+			},
+		},
+	}
+}
+
+type Option func(g *Generator)
+
+// WithServers declares the servers which serve the API
+func WithServers(urls ...string) Option {
+	return func(g *Generator) {
+		for _, url := range urls {
+			g.servers = append(g.servers, Server{URL: url})
+		}
+	}
+}
+
+// WithRawResponse stops wrapping response schema into the response body of package resp
+func WithRawResponse() Option {
+	return func(g *Generator) {
+		g.rawResponse = true
+	}
+}
+
+// WithEnvelope sets the schema of the global envelope set by resp.SetEnvelope, default is StandardEnvelope.
+// It is also used for the routes whose resp.ProblemEnvelope has a custom success envelope.
+func WithEnvelope(envelope EnvelopeSchema) Option {
+	return func(g *Generator) {
+		g.envelope = envelope
+	}
+}
+
+// WithProblemDetails declares the global envelope is resp.ProblemEnvelope, error responses are problem details.
+func WithProblemDetails() Option {
+	return func(g *Generator) {
+		g.problem = true
+	}
+}
+
+// New returns a new OpenAPI document generator
+func New(info Info, opts ...Option) *Generator {
+	g := &Generator{info: info, envelope: StandardEnvelope}
+	for _, opt := range opts {
+		opt(g)
+	}
+	if g.info.Title == "" {
+		g.info.Title = "ginx"
+	}
+	if g.info.Version == "" {
+		g.info.Version = "v1"
+	}
+	return g
+}
+
+// Generator generates OpenAPI 3.1 document by walking the route tree and reading well-known metadata,
+// see ginx.MetaSummary, ginx.MetaTags, ginx.MetaRequest, ginx.MetaResponse and so on.
+type Generator struct {
+	info        Info
+	servers     []Server
+	rawResponse bool
+	envelope    EnvelopeSchema
+	problem     bool
+}
+
+// Generate walks the group and its subgroups, then returns the document
+func (g *Generator) Generate(group *ginx.RouterGroup) *Document {
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    g.info,
+		Servers: g.servers,
+		Paths:   make(map[string]*PathItem),
+	}
+
+	registry := newSchemaRegistry()
+
+	tagSet := make(map[string]struct{})
+	group.Walk(func(info ginx.RouteInfo) {
+		if info.IsGroup {
+			return
+		}
+		routePath, pathParams := convertPath(info.FullPath)
+		item, ok := doc.Paths[routePath]
+		if !ok {
+			item = new(PathItem)
+			doc.Paths[routePath] = item
+		}
+		slot := item.operation(info.Method)
+		if slot == nil {
+			return
+		}
+		op := g.operation(registry, info, pathParams)
+		*slot = op
+
+		for _, tag := range op.Tags {
+			if _, exist := tagSet[tag]; !exist {
+				tagSet[tag] = struct{}{}
+				doc.Tags = append(doc.Tags, Tag{Name: tag})
+			}
+		}
+	})
+
+	if len(registry.schemas) > 0 {
+		doc.Components = &Components{Schemas: registry.schemas}
+	}
+	return doc
+}
+
+// operation builds operation from route info
+func (g *Generator) operation(registry *schemaRegistry, info ginx.RouteInfo, pathParams []string) *Operation {
+	meta := info.Meta
+	op := &Operation{
+		Summary:     ginx.MetaSummary.ShouldGet(meta),
+		Description: ginx.MetaDescription.ShouldGet(meta),
+		OperationId: ginx.MetaOperationId.ShouldGet(meta),
+		Deprecated:  ginx.MetaDeprecated.ShouldGet(meta),
+		Tags:        ginx.MetaTags.ShouldGet(meta),
+		Responses:   make(map[string]*Response),
+	}
+
+	// request
+	if reqType := typeOf(ginx.MetaRequest.ShouldGet(meta)); reqType != nil {
+		op.Parameters = registry.parameters(reqType)
+		if info.Method != methods.Get && info.Method != methods.Head && hasBody(reqType) {
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content: map[string]*MediaType{
+					mimes.ApplicationJSON: {Schema: registry.schemaOf(reqType)},
+				},
+			}
+		}
+	}
+
+	// path parameters those are not declared in request type
+	for _, name := range pathParams {
+		declared := false
+		for _, param := range op.Parameters {
+			if param.In == inPath && param.Name == name {
+				declared = true
+				break
+			}
+		}
+		if !declared {
+			op.Parameters = append(op.Parameters, &Parameter{Name: name, In: inPath, Required: true, Schema: &Schema{Type: "string"}})
+		}
+	}
+
+	// response
+	var dataSchema *Schema
+	if resType := typeOf(ginx.MetaResponse.ShouldGet(meta)); resType != nil {
+		dataSchema = registry.schemaOf(resType)
+	}
+
+	okResponse := &Response{Description: status.OK.String()}
+	if g.rawResponse {
+		if dataSchema != nil {
+			okResponse.Content = map[string]*MediaType{mimes.ApplicationJSON: {Schema: dataSchema}}
+		}
+	} else {
+		envelope := g.routeEnvelope(meta)
+		okResponse.Content = map[string]*MediaType{mimes.ApplicationJSON: {Schema: envelope.schema(dataSchema)}}
+		op.Responses["default"] = g.errorResponse(registry, envelope)
+	}
+	op.Responses[strconv.Itoa(status.OK.Code())] = okResponse
+
+	return op
+}
+
+// routeEnvelope describes the response envelope of a route
+type routeEnvelope struct {
+	schema EnvelopeSchema
+	// whether it is the global envelope
+	global bool
+	// whether error responses are problem details
+	problem bool
+}
+
+// routeEnvelope returns the envelope of route declared by ginx.MetaEnvelope, or the global one
+func (g *Generator) routeEnvelope(meta ginx.MetaData) routeEnvelope {
+	switch envelope := ginx.MetaEnvelope.ShouldGet(meta).(type) {
+	case resp.ProblemEnvelope:
+		return routeEnvelope{schema: g.successEnvelope(envelope.Success), problem: true}
+	case *resp.ProblemEnvelope:
+		return routeEnvelope{schema: g.successEnvelope(envelope.Success), problem: true}
+	case resp.StandardEnvelope, *resp.StandardEnvelope:
+		return routeEnvelope{schema: StandardEnvelope}
+	default:
+		return routeEnvelope{schema: g.envelope, global: true, problem: g.problem}
+	}
+}
+
+// successEnvelope returns the schema of success envelope of resp.ProblemEnvelope, which falls back to resp.StandardEnvelope
+func (g *Generator) successEnvelope(envelope resp.Envelope) EnvelopeSchema {
+	switch envelope.(type) {
+	case nil, resp.StandardEnvelope, *resp.StandardEnvelope:
+		return StandardEnvelope
+	default:
+		return g.envelope
+	}
+}
+
+// errorResponse returns the default response of errors, the shared schemas are registered into components
+func (g *Generator) errorResponse(registry *schemaRegistry, envelope routeEnvelope) *Response {
+	if envelope.problem {
+		if _, ok := registry.schemas[problemSchemaName]; !ok {
+			registry.schemas[problemSchemaName] = ProblemSchema()
+		}
+		return &Response{
+			Description: "Error",
+			Content:     map[string]*MediaType{mimes.ApplicationProblemJSON: {Schema: &Schema{Ref: componentsPrefix + problemSchemaName}}},
+		}
+	}
+
+	// the route which does not use the global envelope has its own error schema
+	schema := envelope.schema(nil)
+	if envelope.global {
+		if _, ok := registry.schemas[errorSchemaName]; !ok {
+			registry.schemas[errorSchemaName] = schema
+		}
+		schema = &Schema{Ref: componentsPrefix + errorSchemaName}
+	}
+	return &Response{
+		Description: "Error",
+		Content:     map[string]*MediaType{mimes.ApplicationJSON: {Schema: schema}},
+	}
+}
+
+// Handler returns a handler which serves the document of the server in json format,
+// document is generated at the first request, by that time all the routes have been registered.
+// Only the successfully generated document is cached.
+func (g *Generator) Handler(server *ginx.Server) gin.HandlerFunc {
+	var (
+		mu   sync.Mutex
+		data []byte
+	)
+	return func(ctx *gin.Context) {
+		mu.Lock()
+		if data == nil {
+			doc, err := g.Generate(server.RouterGroup()).JSON()
+			if err != nil {
+				mu.Unlock()
+				_ = ctx.AbortWithError(status.InternalServerError.Code(), err)
+				return
+			}
+			data = doc
+		}
+		mu.Unlock()
+		ctx.Data(status.OK.Code(), mimes.ApplicationJSONUTF8, data)
+	}
+}
+
+// Serve registers a GET route on server engine to serve the document, the route itself will not appear in document.
+func (g *Generator) Serve(server *ginx.Server, path string) {
+	server.Engine().GET(path, g.Handler(server))
+}
+
+// WriteFile generates document of the server and writes into the file
+func (g *Generator) WriteFile(server *ginx.Server, filename string) error {
+	return g.Generate(server.RouterGroup()).WriteFile(filename)
+}
+
+// JSON returns the document in json format
+func (d *Document) JSON() ([]byte, error) {
+	return json.MarshalIndent(d, "", "  ")
+}
+
+// YAML returns the document in yaml format
+func (d *Document) YAML() ([]byte, error) {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	// json is a subset of yaml, decode into yaml node can keep the fields order
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	blockStyle(&node)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteFile writes the document into the file, the format is decided by file extension,
+// .yaml and .yml for yaml format, otherwise json format.
+func (d *Document) WriteFile(filename string) error {
+	var (
+		data []byte
+		err  error
+	)
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		data, err = d.YAML()
+	default:
+		data, err = d.JSON()
+	}
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
+
+func blockStyle(node *yaml.Node) {
+	node.Style &^= yaml.FlowStyle
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!str" {
+		node.Style &^= yaml.DoubleQuotedStyle
+	}
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+// convertPath converts gin route path into OpenAPI path, e.g. /user/:id -> /user/{id},
+// and returns names of path parameters.
+func convertPath(fullPath string) (string, []string) {
+	segments := strings.Split(fullPath, "/")
+	var params []string
+	for i, segment := range segments {
+		if len(segment) > 1 && (segment[0] == ':' || segment[0] == '*') {
+			params = append(params, segment[1:])
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
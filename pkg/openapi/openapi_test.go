@@ -0,0 +1,130 @@
+package openapi
+
+import (
+	"github.com/ginx-contribs/ginx"
+	"github.com/ginx-contribs/ginx/pkg/resp"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+type UserInfoReq struct {
+	Id    int    `uri:"id" binding:"required"`
+	Token string `header:"X-Token"`
+	Lang  string `form:"lang" binding:"oneof=en zh"`
+}
+
+type UpdateUserReq struct {
+	Id   int    `uri:"id"`
+	Name string `json:"name" binding:"required,min=1,max=20"`
+	Age  int    `json:"age" binding:"gte=0"`
+}
+
+type User struct {
+	Id      int      `json:"id"`
+	Name    string   `json:"name"`
+	Friends []*User  `json:"friends"`
+	Tags    []string `json:"tags,omitempty"`
+}
+
+func testServer() *ginx.Server {
+	server := ginx.New()
+	root := server.RouterGroup()
+	user := root.MGroup("user", ginx.M{ginx.MetaTags.V([]string{"user"})})
+	user.MGET("/:id", ginx.M{
+		ginx.MetaSummary.V("get user info"),
+		ginx.MetaRequest.V(UserInfoReq{}),
+		ginx.MetaResponse.V(reflect.TypeOf(User{})),
+	})
+	user.MPUT("/:id", ginx.M{
+		ginx.MetaRequest.V(&UpdateUserReq{}),
+		ginx.MetaResponse.V(resp.Of[[]string](nil)),
+		ginx.MetaDeprecated.V(true),
+	})
+	root.GET("/files/*filepath")
+	return server
+}
+
+func TestGenerate(t *testing.T) {
+	doc := New(Info{Title: "test"}).Generate(testServer().RouterGroup())
+	assert.Equal(t, Version, doc.OpenAPI)
+
+	get := doc.Paths["/user/{id}"].Get
+	if assert.NotNil(t, get) {
+		assert.Equal(t, "get user info", get.Summary)
+		assert.Equal(t, []string{"user"}, get.Tags)
+		assert.Len(t, get.Parameters, 3)
+		assert.Equal(t, inPath, get.Parameters[0].In)
+		assert.True(t, get.Parameters[0].Required)
+		assert.Equal(t, inHeader, get.Parameters[1].In)
+		assert.Equal(t, []any{"en", "zh"}, get.Parameters[2].Schema.Enum)
+		assert.Nil(t, get.RequestBody)
+		data := get.Responses["200"].Content["application/json"].Schema.Properties["data"]
+		assert.Equal(t, componentsPrefix+"User", data.Ref)
+	}
+
+	put := doc.Paths["/user/{id}"].Put
+	if assert.NotNil(t, put) {
+		assert.True(t, put.Deprecated)
+		body := doc.Components.Schemas["UpdateUserReq"]
+		assert.Equal(t, []string{"name"}, body.Required)
+		assert.Equal(t, 20, *body.Properties["name"].MaxLength)
+		assert.NotContains(t, body.Properties, "Id")
+		data := put.Responses["200"].Content["application/json"].Schema.Properties["data"]
+		assert.Equal(t, "array", data.Type)
+	}
+
+	user := doc.Components.Schemas["User"]
+	assert.Equal(t, componentsPrefix+"User", user.Properties["friends"].Items.Ref)
+
+	files := doc.Paths["/files/{filepath}"].Get
+	if assert.NotNil(t, files) {
+		assert.Equal(t, "filepath", files.Parameters[0].Name)
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	generator := New(Info{Title: "test"})
+	server := testServer()
+	dir := t.TempDir()
+	for _, name := range []string{"openapi.json", "openapi.yaml"} {
+		assert.Nil(t, generator.WriteFile(server, filepath.Join(dir, name)))
+	}
+}
+
+func TestSanitizeName(t *testing.T) {
+	assert.Equal(t, "Page_User", sanitizeName("Page[github.com/a/b.User]"))
+	assert.Equal(t, "Pair_int_User", sanitizeName("Pair[int,github.com/a/b.User]"))
+}
+
+func TestEnvelope(t *testing.T) {
+	server := ginx.New()
+	root := server.RouterGroup()
+	root.MGET("/user", ginx.M{ginx.MetaResponse.V(User{})})
+	root.MGET("/problem", ginx.M{ginx.MetaResponse.V(User{}), ginx.MetaEnvelope.V(resp.ProblemEnvelope{})})
+
+	custom := func(data *Schema) *Schema {
+		schema := &Schema{Type: "object", Properties: map[string]*Schema{"success": {Type: "boolean"}}}
+		if data != nil {
+			schema.Properties["result"] = data
+		}
+		return schema
+	}
+	doc := New(Info{Title: "test"}, WithEnvelope(custom)).Generate(root)
+
+	user := doc.Paths["/user"].Get
+	if assert.NotNil(t, user) {
+		assert.Contains(t, user.Responses["200"].Content["application/json"].Schema.Properties, "result")
+		assert.Equal(t, componentsPrefix+errorSchemaName, user.Responses["default"].Content["application/json"].Schema.Ref)
+		assert.Contains(t, doc.Components.Schemas[errorSchemaName].Properties, "success")
+	}
+
+	problem := doc.Paths["/problem"].Get
+	if assert.NotNil(t, problem) {
+		// success envelope of resp.ProblemEnvelope is resp.StandardEnvelope by default
+		assert.Contains(t, problem.Responses["200"].Content["application/json"].Schema.Properties, "data")
+		assert.Equal(t, componentsPrefix+problemSchemaName, problem.Responses["default"].Content["application/problem+json"].Schema.Ref)
+		assert.Contains(t, doc.Components.Schemas[problemSchemaName].Properties, "status")
+	}
+}
@@ -0,0 +1,400 @@
+package openapi
+
+import (
+	"encoding/json"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const componentsPrefix = "#/components/schemas/"
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	durationType   = reflect.TypeOf(time.Duration(0))
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// parameter locations
+const (
+	inPath   = "path"
+	inQuery  = "query"
+	inHeader = "header"
+)
+
+// parameter tags and the location they represent, body fields use json tag.
+var paramTags = []struct {
+	tag string
+	in  string
+}{
+	{"uri", inPath},
+	{"form", inQuery},
+	{"header", inHeader},
+}
+
+// typeOf returns reflect.Type of metadata value, which could be reflect.Type itself or an instance of type.
+func typeOf(v any) reflect.Type {
+	if v == nil {
+		return nil
+	}
+	if t, ok := v.(reflect.Type); ok {
+		return t
+	}
+	// like resp.Typed
+	if typed, ok := v.(interface{ DataType() reflect.Type }); ok {
+		return typed.DataType()
+	}
+	return reflect.TypeOf(v)
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+// schemaRegistry reflects go types into schemas, named struct types will be stored as reusable components.
+type schemaRegistry struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+func newSchemaRegistry() *schemaRegistry {
+	return &schemaRegistry{
+		schemas: make(map[string]*Schema),
+		names:   make(map[reflect.Type]string),
+	}
+}
+
+// schemaOf returns schema of the given type
+func (r *schemaRegistry) schemaOf(t reflect.Type) *Schema {
+	t = indirect(t)
+
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case durationType:
+		return &Schema{Type: "integer", Format: "int64"}
+	case rawMessageType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: r.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: r.schemaOf(t.Elem())}
+	case reflect.Struct:
+		return r.ref(t)
+	default:
+		// interface, func, chan and so on, any value is acceptable
+		return &Schema{}
+	}
+}
+
+// ref returns a reference schema of the named struct type, anonymous struct will be inlined.
+func (r *schemaRegistry) ref(t reflect.Type) *Schema {
+	if t.Name() == "" {
+		return r.objectSchema(t)
+	}
+	name, ok := r.names[t]
+	if !ok {
+		name = r.componentName(t)
+		r.names[t] = name
+		// placeholder for recursive types
+		r.schemas[name] = &Schema{}
+		*r.schemas[name] = *r.objectSchema(t)
+	}
+	return &Schema{Ref: componentsPrefix + name}
+}
+
+// componentName returns an unique name for the type in components
+func (r *schemaRegistry) componentName(t reflect.Type) string {
+	name := sanitizeName(t.Name())
+	if _, exist := r.schemas[name]; !exist {
+		return name
+	}
+	// name conflicts, try to use package name as prefix
+	name = path.Base(t.PkgPath()) + "." + name
+	if _, exist := r.schemas[name]; !exist {
+		return name
+	}
+	for i := 2; ; i++ {
+		if _, exist := r.schemas[name+strconv.Itoa(i)]; !exist {
+			return name + strconv.Itoa(i)
+		}
+	}
+}
+
+// sanitizeName simplifies name of generic type, e.g. Page[github.com/a/b.User] -> Page_User
+func sanitizeName(name string) string {
+	i := strings.IndexByte(name, '[')
+	if i < 0 {
+		return name
+	}
+	var args []string
+	for _, arg := range strings.Split(strings.TrimSuffix(name[i+1:], "]"), ",") {
+		arg = arg[strings.LastIndexByte(arg, '/')+1:]
+		arg = arg[strings.LastIndexByte(arg, '.')+1:]
+		args = append(args, arg)
+	}
+	name = name[:i] + "_" + strings.Join(args, "_")
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r == '.' || r == '-' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9') {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// objectSchema reflects struct fields into properties,
+// fields only bound from path, query or header will be ignored.
+func (r *schemaRegistry) objectSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for _, field := range structFields(t) {
+		if !field.isBody() {
+			continue
+		}
+		name := field.jsonName()
+		prop := r.schemaOf(field.Type)
+		field.applyConstraints(prop)
+		schema.Properties[name] = prop
+		if field.required() {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	return schema
+}
+
+// hasBody reports whether the struct has any field that should be bound from request body
+func hasBody(t reflect.Type) bool {
+	t = indirect(t)
+	if t.Kind() != reflect.Struct {
+		return true
+	}
+	for _, field := range structFields(t) {
+		if field.isBody() {
+			return true
+		}
+	}
+	return false
+}
+
+// parameters reflects fields with uri, form and header tag into parameters
+func (r *schemaRegistry) parameters(t reflect.Type) []*Parameter {
+	t = indirect(t)
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	var params []*Parameter
+	for _, field := range structFields(t) {
+		for _, pt := range paramTags {
+			name, ok := field.tagName(pt.tag)
+			if !ok {
+				continue
+			}
+			schema := r.schemaOf(field.Type)
+			field.applyConstraints(schema)
+			params = append(params, &Parameter{
+				Name:        name,
+				In:          pt.in,
+				Description: field.Tag.Get("description"),
+				Required:    pt.in == inPath || field.required(),
+				Schema:      schema,
+			})
+		}
+	}
+	return params
+}
+
+type structField struct {
+	reflect.StructField
+}
+
+// structFields returns all exported fields of struct, embedded struct fields will be flattened.
+func structFields(t reflect.Type) []structField {
+	var fields []structField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous {
+			ft := indirect(field.Type)
+			if _, tagged := field.Tag.Lookup("json"); !tagged && ft.Kind() == reflect.Struct {
+				fields = append(fields, structFields(ft)...)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		fields = append(fields, structField{field})
+	}
+	return fields
+}
+
+// tagName returns name in the tag, ok is false if tag not found or value is "-"
+func (f structField) tagName(tag string) (string, bool) {
+	value, ok := f.Tag.Lookup(tag)
+	if !ok {
+		return "", false
+	}
+	name, _, _ := strings.Cut(value, ",")
+	if name == "-" {
+		return "", false
+	}
+	if name == "" {
+		name = f.Name
+	}
+	return name, true
+}
+
+func (f structField) jsonName() string {
+	name, _ := f.tagName("json")
+	if name == "" {
+		return f.Name
+	}
+	return name
+}
+
+// isBody reports whether field should be bound from request body
+func (f structField) isBody() bool {
+	if f.Tag.Get("json") == "-" {
+		return false
+	}
+	if _, ok := f.Tag.Lookup("json"); ok {
+		return true
+	}
+	for _, pt := range paramTags {
+		if _, ok := f.Tag.Lookup(pt.tag); ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (f structField) bindingRules() []string {
+	binding := f.Tag.Get("binding")
+	if binding == "" {
+		return nil
+	}
+	rules := strings.Split(binding, ",")
+	// rules after dive is applied to elements
+	for i, rule := range rules {
+		if rule == "dive" {
+			return rules[:i]
+		}
+	}
+	return rules
+}
+
+func (f structField) required() bool {
+	for _, rule := range f.bindingRules() {
+		if rule == "required" {
+			return true
+		}
+	}
+	return false
+}
+
+// applyConstraints converts binding rules into schema constraints
+func (f structField) applyConstraints(schema *Schema) {
+	if desc := f.Tag.Get("description"); desc != "" {
+		schema.Description = desc
+	}
+
+	for _, rule := range f.bindingRules() {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "min", "gte":
+			setMin(schema, param)
+		case "max", "lte":
+			setMax(schema, param)
+		case "len":
+			setMin(schema, param)
+			setMax(schema, param)
+		case "oneof":
+			for _, enum := range strings.Fields(param) {
+				schema.Enum = append(schema.Enum, enumValue(schema.Type, enum))
+			}
+		case "email":
+			schema.Format = "email"
+		case "url", "uri":
+			schema.Format = "uri"
+		case "uuid", "uuid4":
+			schema.Format = "uuid"
+		case "ip":
+			schema.Format = "ip"
+		case "ipv4":
+			schema.Format = "ipv4"
+		case "ipv6":
+			schema.Format = "ipv6"
+		case "hostname":
+			schema.Format = "hostname"
+		}
+	}
+}
+
+func setMin(schema *Schema, param string) {
+	switch schema.Type {
+	case "string":
+		if n, err := strconv.Atoi(param); err == nil {
+			schema.MinLength = &n
+		}
+	case "array":
+		if n, err := strconv.Atoi(param); err == nil {
+			schema.MinItems = &n
+		}
+	case "integer", "number":
+		if n, err := strconv.ParseFloat(param, 64); err == nil {
+			schema.Minimum = &n
+		}
+	}
+}
+
+func setMax(schema *Schema, param string) {
+	switch schema.Type {
+	case "string":
+		if n, err := strconv.Atoi(param); err == nil {
+			schema.MaxLength = &n
+		}
+	case "array":
+		if n, err := strconv.Atoi(param); err == nil {
+			schema.MaxItems = &n
+		}
+	case "integer", "number":
+		if n, err := strconv.ParseFloat(param, 64); err == nil {
+			schema.Maximum = &n
+		}
+	}
+}
+
+func enumValue(typ string, value string) any {
+	switch typ {
+	case "integer":
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			return n
+		}
+	}
+	return value
+}
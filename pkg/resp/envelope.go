@@ -0,0 +1,261 @@
+package resp
+
+import (
+	"encoding/xml"
+	"github.com/gin-gonic/gin"
+	"github.com/ginx-contribs/ginx/constant/headers"
+	"github.com/ginx-contribs/ginx/constant/status"
+	"github.com/ginx-contribs/ginx/pkg/trace"
+	"reflect"
+	"slices"
+	"sync/atomic"
+	"time"
+)
+
+// Body is the standard envelope of response body
+type Body struct {
+	Code  int    `json:"code,omitempty"`
+	Data  any    `json:"data,omitempty"`
+	Msg   string `json:"msg,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// Result is the rendered outcome of Response, which is wrapped into response body by Envelope
+type Result struct {
+	Status status.Status
+	// custom code, it falls back to status code
+	Code int
+	Data any
+	Msg  string
+	// error message which is safe to expose
+	Error string
+	// the original error, it may be statuserr.Error
+	Err error
+}
+
+// Envelope decides the shape of response body
+type Envelope interface {
+	// Wrap returns the response body of result
+	Wrap(ctx *gin.Context, result Result) any
+	// MediaType returns the content type of the body encoded in the MIME type,
+	// empty means the content type of encoder, like application/json.
+	MediaType(result Result, mime string) string
+}
+
+// StandardEnvelope wraps the result into Body
+type StandardEnvelope struct{}
+
+func (StandardEnvelope) Wrap(ctx *gin.Context, result Result) any {
+	return Body{Code: result.Code, Data: result.Data, Msg: result.Msg, Error: result.Error}
+}
+
+func (StandardEnvelope) MediaType(result Result, mime string) string {
+	return ""
+}
+
+const envelopeKey = "github.com/ginx-contribs/ginx/pkg/resp.envelope"
+
+var defaultEnvelope atomic.Pointer[Envelope]
+
+func init() {
+	SetEnvelope(StandardEnvelope{})
+}
+
+// SetEnvelope sets the global envelope, it is StandardEnvelope by default
+func SetEnvelope(envelope Envelope) {
+	defaultEnvelope.Store(&envelope)
+}
+
+// UseEnvelope sets the envelope for the current request, it takes precedence over the global one.
+func UseEnvelope(ctx *gin.Context, envelope Envelope) {
+	ctx.Set(envelopeKey, envelope)
+}
+
+// EnvelopeFromCtx returns the envelope for the current request
+func EnvelopeFromCtx(ctx *gin.Context) Envelope {
+	if v, ok := ctx.Get(envelopeKey); ok {
+		if envelope, ok := v.(Envelope); ok && envelope != nil {
+			return envelope
+		}
+	}
+	return *defaultEnvelope.Load()
+}
+
+// Members is the response body of CustomEnvelope, it is encoded as an object in json, yaml and toml,
+// and as <response> element with members sorted by name in xml.
+type Members map[string]any
+
+func (m Members) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name = xml.Name{Local: "response"}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if err := e.EncodeElement(m[name], xml.StartElement{Name: xml.Name{Local: name}}); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+// FieldFunc computes the value of a member, nil value is omitted unless the member is always present
+type FieldFunc func(ctx *gin.Context, result Result) any
+
+// FieldNames names the members of envelope, the zero values are omitted unless always present, empty name keeps the default, "-" omits the member
+type FieldNames struct {
+	Code  string
+	Data  string
+	Msg   string
+	Error string
+}
+
+type EnvelopeOptions struct {
+	Names FieldNames
+	// names of members which are always present even if they are zero
+	Always []string
+	// extra computed members
+	Fields map[string]FieldFunc
+}
+
+type EnvelopeOption func(options *EnvelopeOptions)
+
+func WithFieldNames(names FieldNames) EnvelopeOption {
+	return func(options *EnvelopeOptions) {
+		options.Names = names
+	}
+}
+
+func WithAlways(names ...string) EnvelopeOption {
+	return func(options *EnvelopeOptions) {
+		options.Always = append(options.Always, names...)
+	}
+}
+
+func WithField(name string, fn FieldFunc) EnvelopeOption {
+	return func(options *EnvelopeOptions) {
+		if options.Fields == nil {
+			options.Fields = make(map[string]FieldFunc)
+		}
+		options.Fields[name] = fn
+	}
+}
+
+// CustomEnvelope wraps the result into Members with configurable member names and extra computed members
+type CustomEnvelope struct {
+	options EnvelopeOptions
+	always  map[string]bool
+}
+
+// NewEnvelope returns a CustomEnvelope with options, for example, an envelope like
+// {"success":true,"code":0,"result":[],"traceId":"...","timestamp":1700000000000} could be built by
+//
+//	resp.NewEnvelope(
+//		resp.WithFieldNames(resp.FieldNames{Data: "result", Msg: "-"}),
+//		resp.WithAlways("code", "result"),
+//		resp.WithField("success", resp.SuccessField),
+//		resp.WithField("traceId", resp.TraceIdField),
+//		resp.WithField("timestamp", resp.TimestampField),
+//	)
+func NewEnvelope(opts ...EnvelopeOption) *CustomEnvelope {
+	var options EnvelopeOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	names := &options.Names
+	for _, name := range []struct {
+		name *string
+		def  string
+	}{{&names.Code, "code"}, {&names.Data, "data"}, {&names.Msg, "msg"}, {&names.Error, "error"}} {
+		if *name.name == "" {
+			*name.name = name.def
+		}
+	}
+
+	always := make(map[string]bool, len(options.Always))
+	for _, name := range options.Always {
+		always[name] = true
+	}
+	return &CustomEnvelope{options: options, always: always}
+}
+
+func (c *CustomEnvelope) Wrap(ctx *gin.Context, result Result) any {
+	members := make(Members, 4+len(c.options.Fields))
+	names := c.options.Names
+	c.set(members, names.Code, result.Code)
+	c.set(members, names.Data, result.Data)
+	c.set(members, names.Msg, result.Msg)
+	c.set(members, names.Error, result.Error)
+	for name, fn := range c.options.Fields {
+		if val := fn(ctx, result); val != nil || c.always[name] {
+			members[name] = val
+		}
+	}
+	return members
+}
+
+func (c *CustomEnvelope) set(members Members, name string, val any) {
+	if name == "-" {
+		return
+	}
+	if c.always[name] || !isZero(val) {
+		members[name] = val
+	}
+}
+
+func (c *CustomEnvelope) MediaType(result Result, mime string) string {
+	return ""
+}
+
+func isZero(val any) bool {
+	if val == nil {
+		return true
+	}
+	rv := reflect.ValueOf(val)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Map:
+		return rv.IsNil()
+	}
+	return rv.IsZero()
+}
+
+// SuccessField reports whether the status code is less than 400
+func SuccessField(ctx *gin.Context, result Result) any {
+	return result.Status.Code() < 400
+}
+
+// TimestampField returns the server time in unix milliseconds
+func TimestampField(ctx *gin.Context, result Result) any {
+	return time.Now().UnixMilli()
+}
+
+// RequestIdField returns the request id in response header, which is set by contribs/requestid,
+// or the one carried by request.
+func RequestIdField(ctx *gin.Context, result Result) any {
+	if id := ctx.Writer.Header().Get(headers.XRequestId); id != "" {
+		return id
+	}
+	if ctx.Request == nil {
+		return nil
+	}
+	if id := ctx.Request.Header.Get(headers.XRequestId); id != "" {
+		return id
+	}
+	return nil
+}
+
+// TraceIdField returns the trace id of the span in request context, see contribs/tracing
+func TraceIdField(ctx *gin.Context, result Result) any {
+	if ctx.Request == nil {
+		return nil
+	}
+	if sc, ok := trace.SpanContextFromContext(ctx.Request.Context()); ok {
+		return sc.TraceID.String()
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package resp
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/ginx-contribs/ginx/constant/headers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCustomEnvelope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	envelope := NewEnvelope(
+		WithFieldNames(FieldNames{Data: "result", Msg: "-"}),
+		WithAlways("code", "result"),
+		WithField("success", SuccessField),
+		WithField("requestId", RequestIdField),
+	)
+
+	serve := func(fn func(ctx *gin.Context)) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(rec)
+		ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+		ctx.Request.Header.Set(headers.XRequestId, "abc")
+		UseEnvelope(ctx, envelope)
+		fn(ctx)
+		return rec
+	}
+	decode := func(rec *httptest.ResponseRecorder) map[string]any {
+		var body map[string]any
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		return body
+	}
+
+	rec := serve(func(ctx *gin.Context) {
+		Ok(ctx).Code(0).Data([]string{}).Msg("ignored").JSON()
+	})
+	assert.Equal(t, map[string]any{"code": float64(0), "result": []any{}, "success": true, "requestId": "abc"}, decode(rec))
+
+	rec = serve(func(ctx *gin.Context) {
+		Fail(ctx).Error(errors.New("bad")).JSON()
+	})
+	assert.Equal(t, map[string]any{"code": float64(400), "result": nil, "error": "bad", "success": false, "requestId": "abc"}, decode(rec))
+
+	rec = serve(func(ctx *gin.Context) {
+		Ok(ctx).Data("hello").XML()
+	})
+	assert.Contains(t, rec.Body.String(), "<response><code>200</code><requestId>abc</requestId><result>hello</result><success>true</success></response>")
+
+	rec = serve(func(ctx *gin.Context) {
+		Ok(ctx).Data(map[string]int{"total": 1}).Raw().JSON()
+	})
+	assert.Equal(t, map[string]any{"total": float64(1)}, decode(rec))
+}
@@ -0,0 +1,231 @@
+package resp
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/ginx-contribs/ginx/constant/headers"
+	"github.com/ginx-contribs/ginx/constant/mimes"
+	"github.com/ginx-contribs/ginx/constant/status"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var ErrNotAcceptable = errors.New("none of the accepted media types is supported")
+
+// Encoder writes obj as response body with status code in a specific format
+type Encoder func(ctx *gin.Context, code int, obj any)
+
+// Encoders is a registry of encoders keyed by MIME type, it is used by Response.Render to negotiate format.
+type Encoders struct {
+	mu       sync.RWMutex
+	encoders map[string]Encoder
+	// offers in order of server preference, the first one is the default
+	priority []string
+}
+
+// NewEncoders returns a registry with json, xml, yaml, toml and problem details encoders, json is the default.
+// The problem details encoders are only used when the envelope produces problem details, see Response.Render.
+func NewEncoders() *Encoders {
+	e := &Encoders{encoders: make(map[string]Encoder)}
+	e.Register(mimes.ApplicationJSON, func(ctx *gin.Context, code int, obj any) { ctx.JSON(code, obj) })
+	e.Register(mimes.ApplicationXML, func(ctx *gin.Context, code int, obj any) { ctx.XML(code, obj) })
+	e.Register(mimes.TextXML, func(ctx *gin.Context, code int, obj any) { ctx.XML(code, obj) })
+	e.Register(mimes.ApplicationYAML, func(ctx *gin.Context, code int, obj any) { ctx.YAML(code, obj) })
+	e.Register(mimes.ApplicationXYAML, func(ctx *gin.Context, code int, obj any) { ctx.YAML(code, obj) })
+	e.Register(mimes.ApplicationTOML, func(ctx *gin.Context, code int, obj any) { ctx.TOML(code, obj) })
+	// for the clients which only accept problem details, they are negotiated only if the envelope produces them
+	e.Register(mimes.ApplicationProblemJSON, func(ctx *gin.Context, code int, obj any) { ctx.JSON(code, obj) })
+	e.Register(mimes.ApplicationProblemXML, func(ctx *gin.Context, code int, obj any) { ctx.XML(code, obj) })
+	return e
+}
+
+// DefaultEncoders is used by Response.Render
+var DefaultEncoders = NewEncoders()
+
+// RegisterEncoder registers encoder into DefaultEncoders
+func RegisterEncoder(mime string, encoder Encoder) {
+	DefaultEncoders.Register(mime, encoder)
+}
+
+// SetPriority sets the priority of DefaultEncoders
+func SetPriority(mimes ...string) {
+	DefaultEncoders.SetPriority(mimes...)
+}
+
+// Register registers encoder for the MIME type, the existing one will be replaced,
+// new MIME type is appended to the end of priority.
+func (e *Encoders) Register(mime string, encoder Encoder) {
+	mime = strings.ToLower(mime)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, ok := e.encoders[mime]; !ok {
+		e.priority = append(e.priority, mime)
+	}
+	e.encoders[mime] = encoder
+}
+
+// SetPriority moves the MIME types to the front of priority in order, the first one becomes the default,
+// which is used when request does not specify Accept or accepts anything.
+// The MIME types without encoder are ignored.
+func (e *Encoders) SetPriority(mimes ...string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	priority := make([]string, 0, len(e.priority))
+	for _, mime := range mimes {
+		mime = strings.ToLower(mime)
+		if _, ok := e.encoders[mime]; ok && !slices.Contains(priority, mime) {
+			priority = append(priority, mime)
+		}
+	}
+	for _, mime := range e.priority {
+		if !slices.Contains(priority, mime) {
+			priority = append(priority, mime)
+		}
+	}
+	e.priority = priority
+}
+
+// Default returns the default MIME type and its encoder
+func (e *Encoders) Default() (string, Encoder) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if len(e.priority) == 0 {
+		return "", nil
+	}
+	return e.priority[0], e.encoders[e.priority[0]]
+}
+
+// Negotiate picks the MIME type and encoder by the Accept header value, it returns false if nothing is acceptable.
+// The offer with the highest quality wins, ties are broken by server priority.
+func (e *Encoders) Negotiate(accept string) (string, Encoder, bool) {
+	return e.negotiate(accept, nil)
+}
+
+// negotiate is same as Negotiate, but only the offers allowed by filter are considered if filter is not nil
+func (e *Encoders) negotiate(accept string, filter func(mime string) bool) (string, Encoder, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	offers := e.priority
+	if filter != nil {
+		offers = make([]string, 0, len(e.priority))
+		for _, offer := range e.priority {
+			if filter(offer) {
+				offers = append(offers, offer)
+			}
+		}
+	}
+	if len(offers) == 0 {
+		return "", nil, false
+	}
+
+	ranges := parseAccept(accept)
+	if len(ranges) == 0 {
+		return offers[0], e.encoders[offers[0]], true
+	}
+
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		if q := quality(ranges, offer); q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	if best == "" {
+		return "", nil, false
+	}
+	return best, e.encoders[best], true
+}
+
+type mediaRange struct {
+	typ, subtype string
+	q            float64
+}
+
+// parseAccept parses Accept header value, the parameters except q are ignored
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		typ, subtype, ok := strings.Cut(strings.ToLower(strings.TrimSpace(params[0])), "/")
+		if !ok || typ == "" || subtype == "" {
+			continue
+		}
+		r := mediaRange{typ: typ, subtype: subtype, q: 1}
+		for _, param := range params[1:] {
+			k, v, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.TrimSpace(k) != "q" {
+				continue
+			}
+			if q, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil && q >= 0 && q <= 1 {
+				r.q = q
+			}
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges
+}
+
+// quality returns the quality of offer from the most specific matched range
+func quality(ranges []mediaRange, offer string) float64 {
+	typ, subtype, _ := strings.Cut(offer, "/")
+	q, specificity := 0.0, -1
+	for _, r := range ranges {
+		var s int
+		switch {
+		case r.typ == typ && r.subtype == subtype:
+			s = 2
+		case r.typ == typ && r.subtype == "*":
+			s = 1
+		case r.typ == "*" && r.subtype == "*":
+			s = 0
+		default:
+			continue
+		}
+		if s > specificity {
+			q, specificity = r.q, s
+		}
+	}
+	return q
+}
+
+// envelopeMediaTypes could only be produced by the envelope which declares them by Envelope.MediaType, like ProblemEnvelope
+var envelopeMediaTypes = []string{mimes.ApplicationProblemJSON, mimes.ApplicationProblemXML}
+
+// Render writes response in the format negotiated from Accept header with DefaultEncoders,
+// if nothing is acceptable, it responds 406 Not Acceptable in the default format.
+// The media types like application/problem+json are only offered when the envelope produces them for the response.
+func (resp *Response) Render() {
+	resp.RenderWith(DefaultEncoders)
+}
+
+// RenderWith is same as Render but negotiates with the provided encoders
+func (resp *Response) RenderWith(encoders *Encoders) {
+	resp.ctx.Writer.Header().Add(headers.Vary, headers.Accept)
+	resp.render()
+
+	envelope, result := EnvelopeFromCtx(resp.ctx), resp.result()
+	filter := func(mime string) bool {
+		if !slices.Contains(envelopeMediaTypes, mime) {
+			return true
+		}
+		return !resp.raw && envelope.MediaType(result, mime) == mime
+	}
+
+	mime, encoder, ok := encoders.negotiate(resp.ctx.GetHeader(headers.Accept), filter)
+	if !ok {
+		resp.status = status.NotAcceptable
+		resp.err = ErrNotAcceptable
+		resp.body.Code = 0
+		resp.codeSet = false
+		resp.raw = false
+		resp.body.Data = nil
+		resp.body.Error = ""
+		resp.render()
+		if mime, encoder = encoders.Default(); encoder == nil || !filter(mime) {
+			mime, encoder = mimes.ApplicationJSON, func(ctx *gin.Context, code int, obj any) { ctx.JSON(code, obj) }
+		}
+	}
+	resp.encode(mime, encoder)
+}
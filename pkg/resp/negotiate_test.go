@@ -0,0 +1,116 @@
+package resp
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/ginx-contribs/ginx/constant/mimes"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	encoders := NewEncoders()
+
+	negotiate := func(accept string) string {
+		mime, _, ok := encoders.Negotiate(accept)
+		if !ok {
+			return ""
+		}
+		return mime
+	}
+
+	assert.Equal(t, mimes.ApplicationJSON, negotiate(""))
+	assert.Equal(t, mimes.ApplicationJSON, negotiate("*/*"))
+	assert.Equal(t, mimes.ApplicationXML, negotiate("application/xml"))
+	assert.Equal(t, mimes.ApplicationYAML, negotiate("application/json;q=0.5, application/yaml"))
+	assert.Equal(t, mimes.TextXML, negotiate("text/*, application/*;q=0.2"))
+	assert.Equal(t, mimes.ApplicationXML, negotiate("application/*, application/json;q=0"))
+	assert.Equal(t, "", negotiate("image/png"))
+
+	encoders.Register(mimes.ApplicationMsgPack, func(ctx *gin.Context, code int, obj any) {})
+	encoders.SetPriority(mimes.ApplicationMsgPack, "image/png")
+	assert.Equal(t, mimes.ApplicationMsgPack, negotiate("*/*"))
+	assert.Equal(t, mimes.ApplicationJSON, negotiate("application/json, application/msgpack;q=0.9"))
+}
+
+func TestRender(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	render := func(accept string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(rec)
+		ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+		ctx.Request.Header.Set("Accept", accept)
+		Ok(ctx).Data("hello").Render()
+		return rec
+	}
+
+	rec := render("application/yaml")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "yaml")
+	assert.Equal(t, "Accept", rec.Header().Get("Vary"))
+
+	rec = render("image/png")
+	assert.Equal(t, http.StatusNotAcceptable, rec.Code)
+	var body map[string]any
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, float64(http.StatusNotAcceptable), body["code"])
+	assert.Equal(t, ErrNotAcceptable.Error(), body["error"])
+	assert.Nil(t, body["data"])
+}
+
+func TestRenderProblem(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	render := func(envelope Envelope, accept string, fn func(ctx *gin.Context) *Response) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(rec)
+		ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+		ctx.Request.Header.Set("Accept", accept)
+		UseEnvelope(ctx, envelope)
+		fn(ctx).Render()
+		return rec
+	}
+	fail := func(ctx *gin.Context) *Response { return Fail(ctx).ErrorMsg("bad") }
+	ok := func(ctx *gin.Context) *Response { return Ok(ctx).Data("hello") }
+
+	// standard envelope can not produce problem details
+	rec := render(StandardEnvelope{}, mimes.ApplicationProblemJSON, fail)
+	assert.Equal(t, http.StatusNotAcceptable, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), mimes.ApplicationJSON)
+
+	rec = render(ProblemEnvelope{}, mimes.ApplicationProblemJSON, fail)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, mimes.ApplicationProblemJSON, rec.Header().Get("Content-Type"))
+
+	// success response is not problem details
+	rec = render(ProblemEnvelope{}, "application/problem+json, application/json;q=0.5", ok)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), mimes.ApplicationJSON)
+}
+
+func TestRenderErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	render := func(accept string) *gin.Context {
+		ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+		ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+		ctx.Request.Header.Set("Accept", accept)
+		Fail(ctx).Error(errors.New("bad")).Render()
+		return ctx
+	}
+
+	ctx := render(mimes.ApplicationJSON)
+	if assert.Len(t, ctx.Errors, 1) {
+		assert.EqualError(t, ctx.Errors[0].Err, "bad")
+	}
+
+	// the error is recorded once on 406
+	ctx = render("image/png")
+	if assert.Len(t, ctx.Errors, 1) {
+		assert.ErrorIs(t, ctx.Errors[0].Err, ErrNotAcceptable)
+	}
+}
@@ -0,0 +1,266 @@
+package resp
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/ginx-contribs/ginx/constant/headers"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidPage   = errors.New("invalid page")
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+// PageOptions limits the page size
+type PageOptions struct {
+	// used when limit is not specified, default is 20
+	DefaultLimit int `mapstructure:"defaultLimit"`
+	// larger limit is reduced to it, default is 100
+	MaxLimit int `mapstructure:"maxLimit"`
+}
+
+// DefaultPageOptions is used by PageRequest.Normalize
+var DefaultPageOptions = PageOptions{DefaultLimit: 20, MaxLimit: 100}
+
+// PageRequest is the bindable page request, it is in cursor mode if cursor is present, otherwise offset mode.
+// It could be embedded in request struct.
+type PageRequest struct {
+	Offset int    `form:"offset" json:"offset,omitempty"`
+	Limit  int    `form:"limit" json:"limit,omitempty"`
+	Cursor string `form:"cursor" json:"cursor,omitempty"`
+}
+
+// IsCursor reports whether it is in cursor mode
+func (p PageRequest) IsCursor() bool {
+	return p.Cursor != ""
+}
+
+// Normalize applies the default limit and the max limit, and checks offset.
+func (p *PageRequest) Normalize(options PageOptions) error {
+	if options.DefaultLimit <= 0 {
+		options.DefaultLimit = DefaultPageOptions.DefaultLimit
+	}
+	if options.MaxLimit <= 0 {
+		options.MaxLimit = DefaultPageOptions.MaxLimit
+	}
+
+	if p.Offset < 0 {
+		return fmt.Errorf("%w: negative offset %d", ErrInvalidPage, p.Offset)
+	}
+	if p.Limit < 0 {
+		return fmt.Errorf("%w: negative limit %d", ErrInvalidPage, p.Limit)
+	}
+	if p.Limit == 0 {
+		p.Limit = options.DefaultLimit
+	}
+	p.Limit = min(p.Limit, options.MaxLimit)
+	return nil
+}
+
+// DecodeCursor decodes the opaque cursor into v, see EncodeCursor
+func (p PageRequest) DecodeCursor(v any) error {
+	return DecodeCursor(p.Cursor, v)
+}
+
+// EncodeCursor encodes v into an opaque cursor, which is base64url encoded json
+func EncodeCursor(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// DecodeCursor decodes the cursor encoded by EncodeCursor into v
+func DecodeCursor(cursor string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+	return nil
+}
+
+// ListBody is the data of list response
+type ListBody[T any] struct {
+	Items []T `json:"items"`
+	// total count of items, it is absent if unknown
+	Total      *int64 `json:"total,omitempty"`
+	NextCursor string `json:"nextCursor,omitempty"`
+	PrevCursor string `json:"prevCursor,omitempty"`
+	HasMore    bool   `json:"hasMore"`
+}
+
+// Paged is a list response builder, it renders ListBody in envelope with RFC 8288 Link header.
+type Paged[T any] struct {
+	typed   *Typed[ListBody[T]]
+	page    PageRequest
+	body    ListBody[T]
+	hasMore *bool
+}
+
+// List returns a list response of the page
+func List[T any](ctx *gin.Context, page PageRequest, items []T) *Paged[T] {
+	if items == nil {
+		items = []T{}
+	}
+	return &Paged[T]{typed: Of[ListBody[T]](ctx), page: page, body: ListBody[T]{Items: items}}
+}
+
+// DataType returns the type of ListBody[T], so that it could be used as the value of ginx.MetaResponse
+func (p *Paged[T]) DataType() reflect.Type {
+	return p.typed.DataType()
+}
+
+func (p *Paged[T]) Code(code int) *Paged[T] {
+	p.typed.Code(code)
+	return p
+}
+
+func (p *Paged[T]) Msg(msg string) *Paged[T] {
+	p.typed.Msg(msg)
+	return p
+}
+
+// Total sets the total count, in offset mode, it is used to decide whether there are more items and the last page.
+func (p *Paged[T]) Total(total int64) *Paged[T] {
+	p.body.Total = &total
+	return p
+}
+
+// NextCursor sets the cursor of next page, there are more items if it is not empty.
+func (p *Paged[T]) NextCursor(cursor string) *Paged[T] {
+	p.body.NextCursor = cursor
+	return p
+}
+
+// PrevCursor sets the cursor of previous page
+func (p *Paged[T]) PrevCursor(cursor string) *Paged[T] {
+	p.body.PrevCursor = cursor
+	return p
+}
+
+// HasMore sets whether there are more items explicitly, otherwise it is inferred by total or next cursor,
+// or whether the page is full if neither is known.
+func (p *Paged[T]) HasMore(hasMore bool) *Paged[T] {
+	p.hasMore = &hasMore
+	return p
+}
+
+func (p *Paged[T]) JSON() {
+	p.prepare()
+	p.typed.JSON()
+}
+
+func (p *Paged[T]) XML() {
+	p.prepare()
+	p.typed.XML()
+}
+
+func (p *Paged[T]) YAML() {
+	p.prepare()
+	p.typed.YAML()
+}
+
+func (p *Paged[T]) TOML() {
+	p.prepare()
+	p.typed.TOML()
+}
+
+func (p *Paged[T]) Render() {
+	p.prepare()
+	p.typed.Render()
+}
+
+func (p *Paged[T]) prepare() {
+	body := &p.body
+	switch {
+	case p.hasMore != nil:
+		body.HasMore = *p.hasMore
+	case p.page.IsCursor() || body.NextCursor != "":
+		body.HasMore = body.NextCursor != ""
+	case body.Total != nil:
+		body.HasMore = int64(p.page.Offset+len(body.Items)) < *body.Total
+	default:
+		body.HasMore = p.page.Limit > 0 && len(body.Items) >= p.page.Limit
+	}
+	p.typed.Data(*body)
+
+	if links := p.links(); len(links) > 0 {
+		p.typed.resp.ctx.Writer.Header().Set(headers.Link, strings.Join(links, ", "))
+	}
+}
+
+// links returns the Link header values of first, prev, next and last pages, the targets are relative references
+// built from the current request url.
+func (p *Paged[T]) links() []string {
+	ctx := p.typed.resp.ctx
+	if ctx == nil || ctx.Request == nil {
+		return nil
+	}
+	var (
+		body  = p.body
+		page  = p.page
+		links []string
+	)
+	link := func(rel string, set map[string]string) {
+		u := *ctx.Request.URL
+		query := u.Query()
+		for k, v := range set {
+			if v == "" {
+				query.Del(k)
+			} else {
+				query.Set(k, v)
+			}
+		}
+		u.RawQuery = query.Encode()
+		target := url.URL{Path: u.Path, RawPath: u.RawPath, RawQuery: u.RawQuery}
+		links = append(links, fmt.Sprintf(`<%s>; rel="%s"`, target.String(), rel))
+	}
+
+	// cursor mode
+	if page.IsCursor() || body.NextCursor != "" || body.PrevCursor != "" {
+		link("first", map[string]string{"cursor": "", "offset": ""})
+		if body.PrevCursor != "" {
+			link("prev", map[string]string{"cursor": body.PrevCursor, "offset": ""})
+		}
+		if body.HasMore && body.NextCursor != "" {
+			link("next", map[string]string{"cursor": body.NextCursor, "offset": ""})
+		}
+		return links
+	}
+
+	if page.Limit <= 0 {
+		return nil
+	}
+	limit := strconv.Itoa(page.Limit)
+	link("first", map[string]string{"offset": "0", "limit": limit})
+	if page.Offset > 0 {
+		link("prev", map[string]string{"offset": strconv.Itoa(max(page.Offset-page.Limit, 0)), "limit": limit})
+	}
+	if body.HasMore {
+		link("next", map[string]string{"offset": strconv.Itoa(page.Offset + page.Limit), "limit": limit})
+	}
+	if body.Total != nil {
+		// last page is on the same grid as the current offset, so that it could be reached by paging forward
+		total, offset, size := *body.Total, int64(page.Offset), int64(page.Limit)
+		last := int64(0)
+		switch {
+		case offset < total:
+			last = offset + (total-1-offset)/size*size
+		case total > 0:
+			last = (total - 1) / size * size
+		}
+		link("last", map[string]string{"offset": strconv.FormatInt(last, 10), "limit": limit})
+	}
+	return links
+}
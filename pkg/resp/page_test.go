@@ -0,0 +1,83 @@
+package resp
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/ginx-contribs/ginx/constant/headers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	page := PageRequest{Limit: 500}
+	require.NoError(t, page.Normalize(PageOptions{MaxLimit: 50}))
+	assert.Equal(t, 50, page.Limit)
+
+	page = PageRequest{}
+	require.NoError(t, page.Normalize(PageOptions{}))
+	assert.Equal(t, DefaultPageOptions.DefaultLimit, page.Limit)
+
+	page = PageRequest{Offset: -1}
+	assert.ErrorIs(t, page.Normalize(PageOptions{}), ErrInvalidPage)
+
+	cursor, err := EncodeCursor(map[string]int{"id": 42})
+	require.NoError(t, err)
+	var after map[string]int
+	require.NoError(t, PageRequest{Cursor: cursor}.DecodeCursor(&after))
+	assert.Equal(t, 42, after["id"])
+	assert.ErrorIs(t, DecodeCursor("!", &after), ErrInvalidCursor)
+}
+
+func TestList(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	serve := func(target string, fn func(ctx *gin.Context)) (*httptest.ResponseRecorder, ListBody[int]) {
+		rec := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(rec)
+		ctx.Request = httptest.NewRequest(http.MethodGet, target, nil)
+		fn(ctx)
+		var body struct {
+			Data ListBody[int] `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		return rec, body.Data
+	}
+
+	// offset mode
+	rec, body := serve("/items?offset=20&limit=10&q=a", func(ctx *gin.Context) {
+		List(ctx, PageRequest{Offset: 20, Limit: 10}, []int{1, 2}).Total(45).JSON()
+	})
+	assert.True(t, body.HasMore)
+	assert.Equal(t, int64(45), *body.Total)
+	assert.Equal(t, `</items?limit=10&offset=0&q=a>; rel="first", `+
+		`</items?limit=10&offset=10&q=a>; rel="prev", `+
+		`</items?limit=10&offset=30&q=a>; rel="next", `+
+		`</items?limit=10&offset=40&q=a>; rel="last"`, rec.Header().Get(headers.Link))
+
+	// non-aligned offset, last page is reachable by paging forward
+	rec, _ = serve("/items?offset=5&limit=10", func(ctx *gin.Context) {
+		List(ctx, PageRequest{Offset: 5, Limit: 10}, []int{1, 2}).Total(25).JSON()
+	})
+	assert.Equal(t, `</items?limit=10&offset=0>; rel="first", `+
+		`</items?limit=10&offset=0>; rel="prev", `+
+		`</items?limit=10&offset=15>; rel="next", `+
+		`</items?limit=10&offset=15>; rel="last"`, rec.Header().Get(headers.Link))
+
+	// cursor mode
+	rec, body = serve("/items?cursor=abc&limit=2", func(ctx *gin.Context) {
+		List(ctx, PageRequest{Cursor: "abc", Limit: 2}, []int{1, 2}).NextCursor("def").JSON()
+	})
+	assert.True(t, body.HasMore)
+	assert.Equal(t, "def", body.NextCursor)
+	assert.Equal(t, `</items?limit=2>; rel="first", </items?cursor=def&limit=2>; rel="next"`, rec.Header().Get(headers.Link))
+
+	// last page
+	_, body = serve("/items?cursor=def", func(ctx *gin.Context) {
+		List[int](ctx, PageRequest{Cursor: "def", Limit: 2}, nil).JSON()
+	})
+	assert.False(t, body.HasMore)
+	assert.Equal(t, []int{}, body.Items)
+}
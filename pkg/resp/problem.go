@@ -0,0 +1,122 @@
+package resp
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/ginx-contribs/ginx/constant/mimes"
+	"github.com/ginx-contribs/ginx/pkg/resp/statuserr"
+)
+
+// Problem is the problem details object, see RFC 9457
+type Problem struct {
+	XMLName  xml.Name `json:"-" xml:"urn:ietf:rfc:7807 problem"`
+	Type     string   `json:"type" xml:"type"`
+	Title    string   `json:"title" xml:"title"`
+	Status   int      `json:"status" xml:"status"`
+	Detail   string   `json:"detail,omitempty" xml:"detail,omitempty"`
+	Instance string   `json:"instance,omitempty" xml:"instance,omitempty"`
+	// custom error code
+	Code   int                    `json:"code,omitempty" xml:"code,omitempty"`
+	Errors []statuserr.FieldError `json:"errors,omitempty" xml:"errors>i,omitempty"`
+	// extension members, they are only encoded in json, and can not override the members above
+	Extensions map[string]any `json:"-" xml:"-"`
+}
+
+// MarshalJSON flattens extensions into problem object
+func (p Problem) MarshalJSON() ([]byte, error) {
+	type problem Problem
+	data, err := json.Marshal(problem(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return data, err
+	}
+
+	members := make(map[string]json.RawMessage, len(p.Extensions)+7)
+	for k, v := range p.Extensions {
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		members[k] = raw
+	}
+	var fixed map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fixed); err != nil {
+		return nil, err
+	}
+	for k, v := range fixed {
+		members[k] = v
+	}
+	return json.Marshal(members)
+}
+
+// ProblemEnvelope wraps the error results into Problem, and content type is problem+json or problem+xml.
+// The type, custom code, field errors and extensions come from statuserr.Error.
+type ProblemEnvelope struct {
+	// the type of problem if statuserr.Error does not specify, default is about:blank
+	DefaultType string
+	// returns instance of problem, default is the request path
+	Instance func(ctx *gin.Context) string
+	// envelope for the results which are not error, default is StandardEnvelope
+	Success Envelope
+}
+
+func (p ProblemEnvelope) Wrap(ctx *gin.Context, result Result) any {
+	if !isProblem(result) {
+		return p.success().Wrap(ctx, result)
+	}
+
+	problem := Problem{
+		Type:   p.DefaultType,
+		Title:  result.Status.String(),
+		Status: result.Status.Code(),
+	}
+	if result.Error != problem.Title {
+		problem.Detail = result.Error
+	}
+	if p.Instance != nil {
+		problem.Instance = p.Instance(ctx)
+	} else if ctx.Request != nil {
+		problem.Instance = ctx.Request.URL.Path
+	}
+	if result.Code != result.Status.Code() {
+		problem.Code = result.Code
+	}
+
+	var statusErr statuserr.Error
+	if result.Err != nil && errors.As(result.Err, &statusErr) {
+		if statusErr.Type != "" {
+			problem.Type = statusErr.Type
+		}
+		problem.Errors = statusErr.Fields()
+		problem.Extensions = statusErr.Extensions()
+	}
+	if problem.Type == "" {
+		problem.Type = "about:blank"
+	}
+	return problem
+}
+
+func (p ProblemEnvelope) MediaType(result Result, mime string) string {
+	if !isProblem(result) {
+		return p.success().MediaType(result, mime)
+	}
+	switch mime {
+	case mimes.ApplicationJSON, mimes.ApplicationProblemJSON:
+		return mimes.ApplicationProblemJSON
+	case mimes.ApplicationXML, mimes.TextXML, mimes.ApplicationProblemXML:
+		return mimes.ApplicationProblemXML
+	}
+	return ""
+}
+
+func (p ProblemEnvelope) success() Envelope {
+	if p.Success != nil {
+		return p.Success
+	}
+	return StandardEnvelope{}
+}
+
+func isProblem(result Result) bool {
+	return result.Status.Code() >= 400
+}
@@ -0,0 +1,65 @@
+package resp
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/ginx-contribs/ginx/constant/status"
+	"github.com/ginx-contribs/ginx/pkg/resp/statuserr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProblemEnvelope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	serve := func(fn func(ctx *gin.Context)) (*httptest.ResponseRecorder, map[string]any) {
+		rec := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(rec)
+		ctx.Request = httptest.NewRequest(http.MethodPost, "/orders/1", nil)
+		UseEnvelope(ctx, ProblemEnvelope{})
+		fn(ctx)
+		var body map[string]any
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		return rec, body
+	}
+
+	rec, body := serve(func(ctx *gin.Context) {
+		err := statuserr.BadRequest(errors.New("name is required")).
+			SetCode(1001).
+			SetType("https://example.com/probs/invalid-params").
+			SetFields(statuserr.FieldError{Field: "name", Detail: "name is required"}).
+			SetExtension("balance", 30)
+		Fail(ctx).Error(err).JSON()
+	})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+	assert.Equal(t, map[string]any{
+		"type":     "https://example.com/probs/invalid-params",
+		"title":    "Bad Request",
+		"status":   float64(400),
+		"detail":   "name is required",
+		"instance": "/orders/1",
+		"code":     float64(1001),
+		"errors":   []any{map[string]any{"field": "name", "detail": "name is required"}},
+		"balance":  float64(30),
+	}, body)
+
+	// internal error message is hidden
+	_, body = serve(func(ctx *gin.Context) {
+		InternalError(ctx).Error(errors.New("db is down")).JSON()
+	})
+	assert.Equal(t, "about:blank", body["type"])
+	assert.Equal(t, status.InternalServerError.String(), body["title"])
+	assert.Nil(t, body["detail"])
+
+	// success falls back to standard envelope
+	rec, body = serve(func(ctx *gin.Context) {
+		Ok(ctx).Data("ok").JSON()
+	})
+	assert.Equal(t, "application/json; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "ok", body["data"])
+}
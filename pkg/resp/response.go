@@ -3,6 +3,8 @@ package resp
 import (
 	"errors"
 	"github.com/gin-gonic/gin"
+	"github.com/ginx-contribs/ginx/constant/headers"
+	"github.com/ginx-contribs/ginx/constant/mimes"
 	"github.com/ginx-contribs/ginx/constant/status"
 	"github.com/ginx-contribs/ginx/pkg/resp/statuserr"
 )
@@ -26,17 +28,16 @@ func InternalError(ctx *gin.Context) *Response {
 	return &Response{ctx: ctx, status: status.InternalServerError}
 }
 
-// Response represents a http json response
+// Response represents a http response, the body is wrapped by Envelope
 type Response struct {
-	body struct {
-		Code  int    `json:"code,omitempty"`
-		Data  any    `json:"data,omitempty"`
-		Msg   string `json:"msg,omitempty"`
-		Error string `json:"error,omitempty"`
-	}
+	body Body
 
 	// decide whether to show internal server error message in response body
 	transparent bool
+	// code is set explicitly, zero code is kept
+	codeSet bool
+	// write data without envelope
+	raw bool
 
 	status status.Status
 	err    error
@@ -44,8 +45,10 @@ type Response struct {
 	ctx *gin.Context
 }
 
+// Code sets the custom code, it falls back to status code if not set
 func (resp *Response) Code(code int) *Response {
 	resp.body.Code = code
+	resp.codeSet = true
 	return resp
 }
 
@@ -79,6 +82,12 @@ func (resp *Response) Transparent() *Response {
 	return resp
 }
 
+// Raw writes data as response body directly without envelope
+func (resp *Response) Raw() *Response {
+	resp.raw = true
+	return resp
+}
+
 // render the response body
 func (resp *Response) render() {
 	ctx := resp.ctx
@@ -107,13 +116,10 @@ func (resp *Response) render() {
 				resp.body.Error = status.InternalServerError.String()
 			}
 		}
-
-		// append error into context
-		resp.ctx.Error(resp.err)
 	}
 
 	// code fallback
-	if resp.body.Code == 0 {
+	if resp.body.Code == 0 && !resp.codeSet {
 		resp.body.Code = resp.status.Code()
 	}
 
@@ -124,26 +130,58 @@ func (resp *Response) render() {
 }
 
 func (resp *Response) JSON() {
-	resp.render()
-	resp.ctx.JSON(resp.status.Code(), resp.body)
+	resp.write(mimes.ApplicationJSON, func(ctx *gin.Context, code int, obj any) { ctx.JSON(code, obj) })
 }
 
 func (resp *Response) XML() {
-	resp.render()
-	resp.ctx.XML(resp.status.Code(), resp.body)
+	resp.write(mimes.ApplicationXML, func(ctx *gin.Context, code int, obj any) { ctx.XML(code, obj) })
 }
 
 func (resp *Response) YAML() {
-	resp.render()
-	resp.ctx.YAML(resp.status.Code(), resp.body)
+	resp.write(mimes.ApplicationYAML, func(ctx *gin.Context, code int, obj any) { ctx.YAML(code, obj) })
 }
 
 func (resp *Response) TOML() {
-	resp.render()
-	resp.ctx.TOML(resp.status.Code(), resp.body)
+	resp.write(mimes.ApplicationTOML, func(ctx *gin.Context, code int, obj any) { ctx.TOML(code, obj) })
 }
 
 func (resp *Response) ProtoBuf() {
+	resp.write(mimes.PROTOBUF, func(ctx *gin.Context, code int, obj any) { ctx.ProtoBuf(code, obj) })
+}
+
+// write renders the response, and encodes the body wrapped by envelope in the MIME type
+func (resp *Response) write(mime string, encoder Encoder) {
 	resp.render()
-	resp.ctx.ProtoBuf(resp.status.Code(), resp.body)
+	resp.encode(mime, encoder)
+}
+
+// result returns the rendered result
+func (resp *Response) result() Result {
+	return Result{
+		Status: resp.status,
+		Code:   resp.body.Code,
+		Data:   resp.body.Data,
+		Msg:    resp.body.Msg,
+		Error:  resp.body.Error,
+		Err:    resp.err,
+	}
+}
+
+// encode encodes the rendered body wrapped by envelope in the MIME type, the error is appended into context once here,
+// since the response may be rendered again before encoding, see RenderWith.
+func (resp *Response) encode(mime string, encoder Encoder) {
+	if resp.err != nil {
+		resp.ctx.Error(resp.err)
+	}
+	result := resp.result()
+	if resp.raw {
+		encoder(resp.ctx, resp.status.Code(), result.Data)
+		return
+	}
+	envelope := EnvelopeFromCtx(resp.ctx)
+	if mediaType := envelope.MediaType(result, mime); mediaType != "" {
+		// gin renders do not override the existing content type
+		resp.ctx.Header(headers.ContentType, mediaType)
+	}
+	encoder(resp.ctx, resp.status.Code(), envelope.Wrap(resp.ctx, result))
 }
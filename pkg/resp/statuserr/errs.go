@@ -13,6 +13,31 @@ type Error struct {
 	Status status.Status
 	// custom error code
 	Code int
+	// URI reference identifies the problem type, see RFC 9457
+	Type string
+	// details are kept behind a pointer, so that Error is still comparable
+	details *details
+}
+
+// details holds the uncomparable details of Error, it is never modified once set
+type details struct {
+	fields     []FieldError
+	extensions map[string]any
+}
+
+// clone returns a copy of details for writing
+func (e Error) clone() *details {
+	if e.details == nil {
+		return new(details)
+	}
+	d := *e.details
+	return &d
+}
+
+// FieldError is the error of a field
+type FieldError struct {
+	Field  string `json:"field" xml:"field"`
+	Detail string `json:"detail" xml:"detail"`
 }
 
 func (e Error) SetCode(code int) Error {
@@ -35,6 +60,48 @@ func (e Error) SetStatus(status status.Status) Error {
 	return e
 }
 
+func (e Error) SetType(typ string) Error {
+	e.Type = typ
+	return e
+}
+
+// SetFields sets errors of each field, like params validation errors
+func (e Error) SetFields(fields ...FieldError) Error {
+	d := e.clone()
+	d.fields = fields
+	e.details = d
+	return e
+}
+
+// SetExtension sets an extension member of problem details, the extensions are copied to keep the original error unchanged
+func (e Error) SetExtension(key string, val any) Error {
+	d := e.clone()
+	extensions := make(map[string]any, len(d.extensions)+1)
+	for k, v := range d.extensions {
+		extensions[k] = v
+	}
+	extensions[key] = val
+	d.extensions = extensions
+	e.details = d
+	return e
+}
+
+// Fields returns errors of each field
+func (e Error) Fields() []FieldError {
+	if e.details == nil {
+		return nil
+	}
+	return e.details.fields
+}
+
+// Extensions returns extension members of problem details
+func (e Error) Extensions() map[string]any {
+	if e.details == nil {
+		return nil
+	}
+	return e.details.extensions
+}
+
 func (e Error) Error() string {
 	return e.Err.Error()
 }
@@ -0,0 +1,30 @@
+package statuserr
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var (
+	errNotFound = Status(404).SetError(errors.New("not found"))
+	errInvalid  = BadRequest(errors.New("invalid")).SetFields(FieldError{Field: "name", Detail: "required"}).SetExtension("hint", "retry")
+)
+
+func TestComparable(t *testing.T) {
+	for _, sentinel := range []Error{errNotFound, errInvalid} {
+		var err error = sentinel
+		assert.True(t, err == error(sentinel))
+		assert.True(t, errors.Is(fmt.Errorf("wrap: %w", err), sentinel))
+	}
+	assert.False(t, errors.Is(errInvalid, errNotFound))
+
+	// the original error is not changed
+	derived := errInvalid.SetExtension("id", 1).SetFields()
+	assert.Equal(t, []FieldError{{Field: "name", Detail: "required"}}, errInvalid.Fields())
+	assert.Equal(t, map[string]any{"hint": "retry"}, errInvalid.Extensions())
+	assert.Empty(t, derived.Fields())
+	assert.Equal(t, map[string]any{"hint": "retry", "id": 1}, derived.Extensions())
+	assert.False(t, errors.Is(derived, errInvalid))
+}
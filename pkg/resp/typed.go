@@ -0,0 +1,88 @@
+package resp
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ginx-contribs/ginx/constant/status"
+	"reflect"
+)
+
+// Typed is a response builder whose data type is T, it could be used as the value of ginx.MetaResponse,
+// the api document generator recognizes T by DataType.
+type Typed[T any] struct {
+	resp *Response
+}
+
+// Of returns a typed response with status code 200
+func Of[T any](ctx *gin.Context) *Typed[T] {
+	return &Typed[T]{resp: Ok(ctx)}
+}
+
+// DataType returns the type of T
+func (t *Typed[T]) DataType() reflect.Type {
+	return reflect.TypeFor[T]()
+}
+
+// Response returns the underlying response
+func (t *Typed[T]) Response() *Response {
+	return t.resp
+}
+
+func (t *Typed[T]) Data(data T) *Typed[T] {
+	t.resp.Data(data)
+	return t
+}
+
+func (t *Typed[T]) Code(code int) *Typed[T] {
+	t.resp.Code(code)
+	return t
+}
+
+func (t *Typed[T]) Msg(msg string) *Typed[T] {
+	t.resp.Msg(msg)
+	return t
+}
+
+func (t *Typed[T]) Error(err error) *Typed[T] {
+	t.resp.Error(err)
+	return t
+}
+
+func (t *Typed[T]) ErrorMsg(msg string) *Typed[T] {
+	t.resp.ErrorMsg(msg)
+	return t
+}
+
+func (t *Typed[T]) Status(status status.Status) *Typed[T] {
+	t.resp.Status(status)
+	return t
+}
+
+func (t *Typed[T]) Transparent() *Typed[T] {
+	t.resp.Transparent()
+	return t
+}
+
+func (t *Typed[T]) Raw() *Typed[T] {
+	t.resp.Raw()
+	return t
+}
+
+func (t *Typed[T]) JSON() {
+	t.resp.JSON()
+}
+
+func (t *Typed[T]) XML() {
+	t.resp.XML()
+}
+
+func (t *Typed[T]) YAML() {
+	t.resp.YAML()
+}
+
+func (t *Typed[T]) TOML() {
+	t.resp.TOML()
+}
+
+func (t *Typed[T]) Render() {
+	t.resp.Render()
+}
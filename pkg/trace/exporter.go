@@ -0,0 +1,280 @@
+package trace
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/ginx-contribs/ginx/constant/headers"
+	"github.com/ginx-contribs/ginx/constant/mimes"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Exporter exports the ended spans to backend
+type Exporter interface {
+	Export(ctx context.Context, spans []SpanData) error
+	Shutdown(ctx context.Context) error
+}
+
+// InMemoryExporter keeps the spans in memory, it is useful for tests
+type InMemoryExporter struct {
+	mu    sync.Mutex
+	spans []SpanData
+}
+
+func NewInMemoryExporter() *InMemoryExporter {
+	return new(InMemoryExporter)
+}
+
+func (e *InMemoryExporter) Export(ctx context.Context, spans []SpanData) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+func (e *InMemoryExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// Spans returns the exported spans
+func (e *InMemoryExporter) Spans() []SpanData {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return slices.Clone(e.spans)
+}
+
+// Reset clears the exported spans
+func (e *InMemoryExporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = nil
+}
+
+type OTLPOptions struct {
+	// full url of traces endpoint, default is http://localhost:4318/v1/traces
+	Endpoint string
+	// extra request headers, like authorization
+	Headers map[string]string
+	// service.name of resource
+	ServiceName string
+	// default client has 10 seconds timeout
+	Client *http.Client
+}
+
+type OTLPOption func(options *OTLPOptions)
+
+func WithEndpoint(endpoint string) OTLPOption {
+	return func(options *OTLPOptions) {
+		options.Endpoint = endpoint
+	}
+}
+
+func WithHeaders(headers map[string]string) OTLPOption {
+	return func(options *OTLPOptions) {
+		options.Headers = headers
+	}
+}
+
+func WithServiceName(name string) OTLPOption {
+	return func(options *OTLPOptions) {
+		options.ServiceName = name
+	}
+}
+
+func WithHTTPClient(client *http.Client) OTLPOption {
+	return func(options *OTLPOptions) {
+		options.Client = client
+	}
+}
+
+// OTLPExporter exports spans by OTLP/HTTP with JSON encoding
+type OTLPExporter struct {
+	options OTLPOptions
+}
+
+// NewOTLPExporter returns a new OTLP/HTTP exporter with options
+func NewOTLPExporter(opts ...OTLPOption) *OTLPExporter {
+	var options OTLPOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if options.Endpoint == "" {
+		options.Endpoint = "http://localhost:4318/v1/traces"
+	}
+
+	if options.ServiceName == "" {
+		options.ServiceName = "unknown_service"
+	}
+
+	if options.Client == nil {
+		options.Client = &http.Client{Timeout: time.Second * 10}
+	}
+
+	return &OTLPExporter{options: options}
+}
+
+func (e *OTLPExporter) Export(ctx context.Context, spans []SpanData) error {
+	if len(spans) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(e.encode(spans))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.options.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set(headers.ContentType, mimes.ApplicationJSON)
+	for k, v := range e.options.Headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := e.options.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("otlp: unexpected status %d: %s", res.StatusCode, msg)
+	}
+	_, _ = io.Copy(io.Discard, res.Body)
+	return nil
+}
+
+func (e *OTLPExporter) Shutdown(ctx context.Context) error {
+	e.options.Client.CloseIdleConnections()
+	return nil
+}
+
+// otlp json encoding, see https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding
+type (
+	otlpRequest struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+
+	otlpResource struct {
+		Attributes []otlpKeyValue `json:"attributes"`
+	}
+
+	otlpScopeSpans struct {
+		Scope struct {
+			Name string `json:"name"`
+		} `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+
+	otlpSpan struct {
+		TraceID           string         `json:"traceId"`
+		SpanID            string         `json:"spanId"`
+		ParentSpanID      string         `json:"parentSpanId,omitempty"`
+		TraceState        string         `json:"traceState,omitempty"`
+		Name              string         `json:"name"`
+		Kind              int            `json:"kind"`
+		StartTimeUnixNano string         `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+		Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+		Events            []otlpEvent    `json:"events,omitempty"`
+		Status            otlpStatus     `json:"status"`
+	}
+
+	otlpEvent struct {
+		TimeUnixNano string         `json:"timeUnixNano"`
+		Name         string         `json:"name"`
+		Attributes   []otlpKeyValue `json:"attributes,omitempty"`
+	}
+
+	otlpStatus struct {
+		Code    int    `json:"code"`
+		Message string `json:"message,omitempty"`
+	}
+
+	otlpKeyValue struct {
+		Key   string         `json:"key"`
+		Value map[string]any `json:"value"`
+	}
+)
+
+func (e *OTLPExporter) encode(spans []SpanData) otlpRequest {
+	scope := otlpScopeSpans{Spans: make([]otlpSpan, 0, len(spans))}
+	scope.Scope.Name = "github.com/ginx-contribs/ginx"
+	for _, span := range spans {
+		s := otlpSpan{
+			TraceID:    span.SpanContext.TraceID.String(),
+			SpanID:     span.SpanContext.SpanID.String(),
+			TraceState: span.SpanContext.TraceState,
+			Name:       span.Name,
+			// otlp kind starts from unspecified
+			Kind:              int(span.Kind) + 1,
+			StartTimeUnixNano: strconv.FormatInt(span.StartTime.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.EndTime.UnixNano(), 10),
+			Attributes:        otlpAttributes(span.Attributes),
+			Status:            otlpStatus{Code: int(span.Status), Message: span.StatusMessage},
+		}
+		if span.Parent.IsValid() {
+			s.ParentSpanID = span.Parent.String()
+		}
+		for _, event := range span.Events {
+			s.Events = append(s.Events, otlpEvent{
+				TimeUnixNano: strconv.FormatInt(event.Time.UnixNano(), 10),
+				Name:         event.Name,
+				Attributes:   otlpAttributes(event.Attributes),
+			})
+		}
+		scope.Spans = append(scope.Spans, s)
+	}
+
+	resource := otlpResource{Attributes: otlpAttributes(map[string]any{"service.name": e.options.ServiceName})}
+	return otlpRequest{ResourceSpans: []otlpResourceSpans{{Resource: resource, ScopeSpans: []otlpScopeSpans{scope}}}}
+}
+
+func otlpAttributes(attrs map[string]any) []otlpKeyValue {
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	kvs := make([]otlpKeyValue, 0, len(keys))
+	for _, k := range keys {
+		kvs = append(kvs, otlpKeyValue{Key: k, Value: otlpValue(attrs[k])})
+	}
+	return kvs
+}
+
+func otlpValue(v any) map[string]any {
+	switch v := v.(type) {
+	case string:
+		return map[string]any{"stringValue": v}
+	case bool:
+		return map[string]any{"boolValue": v}
+	case int:
+		return map[string]any{"intValue": strconv.FormatInt(int64(v), 10)}
+	case int64:
+		return map[string]any{"intValue": strconv.FormatInt(v, 10)}
+	case float64:
+		return map[string]any{"doubleValue": v}
+	case []string:
+		values := make([]map[string]any, 0, len(v))
+		for _, s := range v {
+			values = append(values, otlpValue(s))
+		}
+		return map[string]any{"arrayValue": map[string]any{"values": values}}
+	default:
+		return map[string]any{"stringValue": fmt.Sprint(v)}
+	}
+}
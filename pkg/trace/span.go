@@ -0,0 +1,359 @@
+package trace
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"log/slog"
+	"maps"
+	"sync"
+	"time"
+)
+
+type SpanKind int
+
+const (
+	SpanKindInternal SpanKind = iota
+	SpanKindServer
+	SpanKindClient
+)
+
+type StatusCode int
+
+const (
+	StatusUnset StatusCode = iota
+	StatusOK
+	StatusError
+)
+
+// Event is a timestamped annotation of span
+type Event struct {
+	Name       string
+	Time       time.Time
+	Attributes map[string]any
+}
+
+// SpanData is the snapshot of an ended span, which is passed to exporter
+type SpanData struct {
+	Name          string
+	Kind          SpanKind
+	SpanContext   SpanContext
+	Parent        SpanID
+	StartTime     time.Time
+	EndTime       time.Time
+	Attributes    map[string]any
+	Events        []Event
+	Status        StatusCode
+	StatusMessage string
+}
+
+// Span records an operation, it is exported when it is ended if sampled
+type Span struct {
+	tracer *Tracer
+
+	mu    sync.Mutex
+	data  SpanData
+	ended bool
+}
+
+// SpanContext returns the span context to propagate
+func (s *Span) SpanContext() SpanContext {
+	return s.data.SpanContext
+}
+
+// IsRecording reports whether the span is sampled and not ended
+func (s *Span) IsRecording() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !s.ended && s.data.SpanContext.Sampled()
+}
+
+func (s *Span) SetName(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Name = name
+}
+
+func (s *Span) SetAttribute(key string, val any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.data.Attributes == nil {
+		s.data.Attributes = make(map[string]any)
+	}
+	s.data.Attributes[key] = val
+}
+
+func (s *Span) AddEvent(name string, attrs map[string]any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Events = append(s.data.Events, Event{Name: name, Time: time.Now(), Attributes: attrs})
+}
+
+// RecordError adds an exception event, it does not change the status
+func (s *Span) RecordError(err error) {
+	if err == nil {
+		return
+	}
+	s.AddEvent("exception", map[string]any{
+		"exception.type":    fmt.Sprintf("%T", err),
+		"exception.message": err.Error(),
+	})
+}
+
+// SetStatus sets the status, the message is only kept for error status
+func (s *Span) SetStatus(code StatusCode, msg string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Status = code
+	if code == StatusError {
+		s.data.StatusMessage = msg
+	} else {
+		s.data.StatusMessage = ""
+	}
+}
+
+// End ends the span and exports it if sampled, only the first call takes effect
+func (s *Span) End() {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.EndTime = time.Now()
+	data := s.data
+	data.Attributes = maps.Clone(s.data.Attributes)
+	s.mu.Unlock()
+
+	if data.SpanContext.Sampled() {
+		s.tracer.export(data)
+	}
+}
+
+type Options struct {
+	// exporter of the sampled spans, spans are dropped if nil
+	Exporter Exporter
+	// ratio of root spans to sample, in range [0, 1], default is 1, child spans follow the parent
+	SampleRatio float64
+	// spans are exported in background by batch, max size of a batch, default is 512
+	BatchSize int
+	// max interval between two batches, default is 5 seconds
+	FlushInterval time.Duration
+	// export every span synchronously when it is ended, it blocks the caller of Span.End,
+	// only use it with a fast exporter like InMemoryExporter, e.g. in tests.
+	SyncExport bool
+}
+
+type Option func(options *Options)
+
+func WithExporter(exporter Exporter) Option {
+	return func(options *Options) {
+		options.Exporter = exporter
+	}
+}
+
+func WithSampleRatio(ratio float64) Option {
+	return func(options *Options) {
+		options.SampleRatio = ratio
+	}
+}
+
+func WithBatch(size int, interval time.Duration) Option {
+	return func(options *Options) {
+		options.BatchSize = size
+		options.FlushInterval = interval
+	}
+}
+
+func WithSyncExport() Option {
+	return func(options *Options) {
+		options.SyncExport = true
+	}
+}
+
+// Tracer creates spans and exports them
+type Tracer struct {
+	options Options
+
+	// guards queue from sending after closed
+	mu     sync.RWMutex
+	closed bool
+	queue  chan SpanData
+	done   chan struct{}
+}
+
+// NewTracer returns a new tracer with options
+func NewTracer(opts ...Option) *Tracer {
+	options := Options{SampleRatio: 1}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if options.BatchSize <= 0 {
+		options.BatchSize = 512
+	}
+	if options.FlushInterval <= 0 {
+		options.FlushInterval = time.Second * 5
+	}
+
+	t := &Tracer{options: options, done: make(chan struct{})}
+	if !options.SyncExport && options.Exporter != nil {
+		t.queue = make(chan SpanData, options.BatchSize*4)
+		go t.loop()
+	} else {
+		close(t.done)
+	}
+	return t
+}
+
+type SpanOptions struct {
+	Kind SpanKind
+	// parent extracted from incoming request, it takes precedence over the span in context
+	RemoteParent SpanContext
+	Attributes   map[string]any
+}
+
+type SpanOption func(options *SpanOptions)
+
+func WithKind(kind SpanKind) SpanOption {
+	return func(options *SpanOptions) {
+		options.Kind = kind
+	}
+}
+
+func WithRemoteParent(parent SpanContext) SpanOption {
+	return func(options *SpanOptions) {
+		options.RemoteParent = parent
+	}
+}
+
+func WithAttributes(attrs map[string]any) SpanOption {
+	return func(options *SpanOptions) {
+		options.Attributes = attrs
+	}
+}
+
+// Start starts a span as the child of the parent in options or context, and returns the context carrying it.
+func (t *Tracer) Start(ctx context.Context, name string, opts ...SpanOption) (context.Context, *Span) {
+	var options SpanOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	parent := options.RemoteParent
+	if !parent.IsValid() {
+		parent, _ = SpanContextFromContext(ctx)
+	}
+
+	sc := SpanContext{SpanID: newSpanID()}
+	if parent.IsValid() {
+		sc.TraceID = parent.TraceID
+		sc.Flags = parent.Flags
+		sc.TraceState = parent.TraceState
+	} else {
+		sc.TraceID = newTraceID()
+		if t.sample(sc.TraceID) {
+			sc.Flags |= FlagsSampled
+		}
+	}
+
+	span := &Span{
+		tracer: t,
+		data: SpanData{
+			Name:        name,
+			Kind:        options.Kind,
+			SpanContext: sc,
+			Parent:      parent.SpanID,
+			StartTime:   time.Now(),
+			Attributes:  maps.Clone(options.Attributes),
+		},
+	}
+	return ContextWithSpan(ctx, span), span
+}
+
+// sample decides by the lower 8 bytes of trace id, so that the decision is consistent for the same trace
+func (t *Tracer) sample(id TraceID) bool {
+	switch ratio := t.options.SampleRatio; {
+	case ratio >= 1:
+		return true
+	case ratio <= 0:
+		return false
+	default:
+		return binary.BigEndian.Uint64(id[8:])>>1 < uint64(ratio*(1<<63))
+	}
+}
+
+func (t *Tracer) export(data SpanData) {
+	if t.options.Exporter == nil {
+		return
+	}
+	if t.queue == nil {
+		if err := t.options.Exporter.Export(context.Background(), []SpanData{data}); err != nil {
+			slog.Error("trace: failed to export spans", slog.Any("error", err))
+		}
+		return
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.closed {
+		return
+	}
+	select {
+	case t.queue <- data:
+	default:
+		slog.Warn("trace: span queue is full, span is dropped", slog.String("span", data.Name))
+	}
+}
+
+func (t *Tracer) loop() {
+	defer close(t.done)
+	ticker := time.NewTicker(t.options.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]SpanData, 0, t.options.BatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := t.options.Exporter.Export(context.Background(), batch); err != nil {
+			slog.Error("trace: failed to export spans", slog.Any("error", err))
+		}
+		batch = make([]SpanData, 0, t.options.BatchSize)
+	}
+
+	for {
+		select {
+		case data, ok := <-t.queue:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, data)
+			if len(batch) >= t.options.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// Shutdown flushes the queued spans and shuts down the exporter, spans ended after shutdown are dropped.
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	t.mu.Lock()
+	if !t.closed && t.queue != nil {
+		close(t.queue)
+	}
+	t.closed = true
+	t.mu.Unlock()
+
+	select {
+	case <-t.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if t.options.Exporter == nil {
+		return nil
+	}
+	return t.options.Exporter.Shutdown(ctx)
+}
@@ -0,0 +1,174 @@
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/ginx-contribs/ginx/constant/headers"
+	"net/http"
+	"strings"
+)
+
+var ErrInvalidTraceParent = errors.New("invalid traceparent")
+
+// TraceID is the W3C trace id
+type TraceID [16]byte
+
+func (t TraceID) IsValid() bool {
+	return t != TraceID{}
+}
+
+func (t TraceID) String() string {
+	return hex.EncodeToString(t[:])
+}
+
+// SpanID is the W3C parent id
+type SpanID [8]byte
+
+func (s SpanID) IsValid() bool {
+	return s != SpanID{}
+}
+
+func (s SpanID) String() string {
+	return hex.EncodeToString(s[:])
+}
+
+// FlagsSampled is the sampled bit of trace flags
+const FlagsSampled byte = 0x01
+
+// SpanContext is the part of span which is propagated across processes
+type SpanContext struct {
+	TraceID    TraceID
+	SpanID     SpanID
+	Flags      byte
+	TraceState string
+	// extracted from incoming request
+	Remote bool
+}
+
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+func (sc SpanContext) Sampled() bool {
+	return sc.Flags&FlagsSampled != 0
+}
+
+// TraceParent returns the traceparent header value, like 00-{trace-id}-{span-id}-{flags}
+func (sc SpanContext) TraceParent() string {
+	return fmt.Sprintf("00-%s-%s-%02x", sc.TraceID, sc.SpanID, sc.Flags)
+}
+
+// ParseTraceParent parses the traceparent header value, see https://www.w3.org/TR/trace-context/#traceparent-header
+func ParseTraceParent(value string) (SpanContext, error) {
+	var sc SpanContext
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return sc, ErrInvalidTraceParent
+	}
+	// version 00 has exactly four fields, future versions may append more
+	if parts[0] == "00" && len(parts) != 4 {
+		return sc, ErrInvalidTraceParent
+	}
+
+	var version, flags [1]byte
+	if !decodeHex(version[:], parts[0]) || !decodeHex(sc.TraceID[:], parts[1]) ||
+		!decodeHex(sc.SpanID[:], parts[2]) || !decodeHex(flags[:], parts[3]) {
+		return sc, ErrInvalidTraceParent
+	}
+	if !sc.IsValid() {
+		return sc, ErrInvalidTraceParent
+	}
+	sc.Flags = flags[0]
+	sc.Remote = true
+	return sc, nil
+}
+
+// only lowercase hex of exact length is accepted
+func decodeHex(dst []byte, s string) bool {
+	if len(s) != hex.EncodedLen(len(dst)) || strings.ToLower(s) != s {
+		return false
+	}
+	_, err := hex.Decode(dst, []byte(s))
+	return err == nil
+}
+
+// Extract extracts the span context from traceparent and tracestate headers
+func Extract(header http.Header) (SpanContext, bool) {
+	sc, err := ParseTraceParent(header.Get(headers.Traceparent))
+	if err != nil {
+		return SpanContext{}, false
+	}
+	sc.TraceState = strings.Join(header.Values(headers.Tracestate), ",")
+	return sc, true
+}
+
+// Inject sets traceparent and tracestate headers with the span context
+func Inject(header http.Header, sc SpanContext) {
+	if !sc.IsValid() {
+		return
+	}
+	header.Set(headers.Traceparent, sc.TraceParent())
+	if sc.TraceState != "" {
+		header.Set(headers.Tracestate, sc.TraceState)
+	} else {
+		header.Del(headers.Tracestate)
+	}
+}
+
+type spanCtxKey struct{}
+
+// ContextWithSpan returns a new context carrying the span
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	return context.WithValue(ctx, spanCtxKey{}, span)
+}
+
+// SpanFromContext returns the span in context, it returns nil if not found
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanCtxKey{}).(*Span)
+	return span
+}
+
+// SpanContextFromContext returns the span context of the span in context
+func SpanContextFromContext(ctx context.Context) (SpanContext, bool) {
+	if span := SpanFromContext(ctx); span != nil {
+		return span.SpanContext(), true
+	}
+	return SpanContext{}, false
+}
+
+// Transport is a http.RoundTripper which injects the span context in request context into outgoing requests
+type Transport struct {
+	// http.DefaultTransport is used if nil
+	Base http.RoundTripper
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if sc, ok := SpanContextFromContext(req.Context()); ok {
+		req = req.Clone(req.Context())
+		Inject(req.Header, sc)
+	}
+	return base.RoundTrip(req)
+}
+
+func newTraceID() TraceID {
+	var id TraceID
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
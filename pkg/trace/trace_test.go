@@ -0,0 +1,129 @@
+package trace
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	sc, err := ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	require.NoError(t, err)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID.String())
+	assert.Equal(t, "00f067aa0ba902b7", sc.SpanID.String())
+	assert.True(t, sc.Sampled())
+	assert.True(t, sc.Remote)
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", sc.TraceParent())
+
+	for _, value := range []string{
+		"",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+	} {
+		_, err := ParseTraceParent(value)
+		assert.ErrorIs(t, err, ErrInvalidTraceParent, value)
+	}
+	// future versions may have more fields
+	_, err = ParseTraceParent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra")
+	assert.NoError(t, err)
+}
+
+func TestTracer(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	tracer := NewTracer(WithExporter(exporter), WithSyncExport())
+
+	ctx, root := tracer.Start(context.Background(), "root")
+	_, child := tracer.Start(ctx, "child")
+	child.End()
+	root.End()
+	root.End()
+
+	spans := exporter.Spans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "child", spans[0].Name)
+	assert.Equal(t, root.SpanContext().TraceID, spans[0].SpanContext.TraceID)
+	assert.Equal(t, root.SpanContext().SpanID, spans[0].Parent)
+	assert.False(t, spans[1].Parent.IsValid())
+
+	// not sampled
+	exporter.Reset()
+	_, span := NewTracer(WithExporter(exporter), WithSyncExport(), WithSampleRatio(0)).Start(context.Background(), "dropped")
+	assert.False(t, span.IsRecording())
+	span.End()
+	assert.Empty(t, exporter.Spans())
+
+	// propagation by transport
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+	}))
+	defer server.Close()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	res, err := (&http.Client{Transport: &Transport{}}).Do(req)
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, root.SpanContext().TraceParent(), traceparent)
+}
+
+func TestOTLPExporter(t *testing.T) {
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "token", r.Header.Get("Authorization"))
+		_ = json.NewDecoder(r.Body).Decode(&body)
+	}))
+	defer server.Close()
+
+	exporter := NewOTLPExporter(WithEndpoint(server.URL), WithServiceName("orders"), WithHeaders(map[string]string{"Authorization": "token"}))
+	tracer := NewTracer(WithExporter(exporter), WithBatch(10, time.Hour))
+	_, span := tracer.Start(context.Background(), "GET /orders", WithKind(SpanKindServer), WithAttributes(map[string]any{"http.response.status_code": 200}))
+	span.SetStatus(StatusError, "boom")
+	span.End()
+	require.NoError(t, tracer.Shutdown(context.Background()))
+
+	resourceSpans := body["resourceSpans"].([]any)[0].(map[string]any)
+	assert.Equal(t, "orders", resourceSpans["resource"].(map[string]any)["attributes"].([]any)[0].(map[string]any)["value"].(map[string]any)["stringValue"])
+	s := resourceSpans["scopeSpans"].([]any)[0].(map[string]any)["spans"].([]any)[0].(map[string]any)
+	assert.Equal(t, span.SpanContext().TraceID.String(), s["traceId"])
+	assert.Equal(t, float64(2), s["kind"])
+	assert.Equal(t, map[string]any{"code": float64(2), "message": "boom"}, s["status"])
+	assert.Equal(t, "200", s["attributes"].([]any)[0].(map[string]any)["value"].(map[string]any)["intValue"])
+}
+
+type blockingExporter struct {
+	InMemoryExporter
+	release chan struct{}
+}
+
+func (e *blockingExporter) Export(ctx context.Context, spans []SpanData) error {
+	<-e.release
+	return e.InMemoryExporter.Export(ctx, spans)
+}
+
+func TestBackgroundExport(t *testing.T) {
+	exporter := &blockingExporter{release: make(chan struct{})}
+	tracer := NewTracer(WithExporter(exporter), WithBatch(1, time.Hour))
+
+	_, span := tracer.Start(context.Background(), "slow")
+	ended := make(chan struct{})
+	go func() {
+		span.End()
+		close(ended)
+	}()
+	select {
+	case <-ended:
+	case <-time.After(time.Second):
+		t.Fatal("span.End is blocked by exporter")
+	}
+
+	close(exporter.release)
+	require.NoError(t, tracer.Shutdown(context.Background()))
+	assert.Len(t, exporter.Spans(), 1)
+}
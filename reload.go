@@ -0,0 +1,130 @@
+package ginx
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ginx-contribs/ginx/pkg/config"
+	"log/slog"
+	"reflect"
+	"strings"
+)
+
+var ErrNoConfig = errors.New("config is not configured, see WithConfig")
+
+// watcher decodes the new value from snapshot, apply is nil if the value is not changed
+type watcher interface {
+	prepare(snapshot *config.Snapshot) (apply func(), err error)
+}
+
+type typedWatcher[T any] struct {
+	key     string
+	current T
+	fn      func(T)
+}
+
+func (w *typedWatcher[T]) prepare(snapshot *config.Snapshot) (func(), error) {
+	var value T
+	if err := snapshot.Decode(w.key, &value); err != nil {
+		return nil, fmt.Errorf("%s: %w", w.key, err)
+	}
+	if reflect.DeepEqual(value, w.current) {
+		return nil, nil
+	}
+	return func() {
+		w.current = value
+		w.fn(value)
+	}, nil
+}
+
+// Watch decodes the config of key into T and returns it, then fn will be called with the new value
+// if it is changed after server reloading. The config source should be configured by WithConfig.
+//
+//	handler := cors.NewHandler(cors.Options{})
+//	cfg, err := ginx.Watch(server, "cors", func(cfg cors.Config) {
+//		handler.Update(cfg.Options())
+//	})
+func Watch[T any](s *Server, key string, fn func(T)) (T, error) {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	var value T
+	if s.configOpts == nil {
+		return value, ErrNoConfig
+	}
+	if s.snapshot == nil {
+		snapshot, err := config.Read(s.configOpts...)
+		if err != nil {
+			return value, err
+		}
+		s.snapshot = snapshot
+	}
+	if err := s.snapshot.Decode(key, &value); err != nil {
+		return value, fmt.Errorf("%s: %w", key, err)
+	}
+	s.watchers = append(s.watchers, &typedWatcher[T]{key: key, current: value, fn: fn})
+	return value, nil
+}
+
+// Reload re-reads the config, validates it and pushes the changes to watchers, nothing is applied if any of them is invalid.
+// Server options can not be changed at runtime, the keys of changed ones are returned as requiring restart.
+func (s *Server) Reload() (restartRequired []string, err error) {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	if s.configOpts == nil {
+		return nil, ErrNoConfig
+	}
+	snapshot, err := config.Read(s.configOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// server options are compared only if they are loaded from the same config
+	var copts config.Options
+	for _, opt := range s.configOpts {
+		opt(&copts)
+	}
+	if copts.Key != "" && snapshot.Has(copts.Key) {
+		var options Options
+		if err := snapshot.Decode(copts.Key, &options); err != nil {
+			return nil, fmt.Errorf("%s: %w", copts.Key, err)
+		}
+		restartRequired = diffOptions(s.configured, options)
+	}
+
+	var applies []func()
+	for _, w := range s.watchers {
+		apply, err := w.prepare(snapshot)
+		if err != nil {
+			return nil, err
+		}
+		if apply != nil {
+			applies = append(applies, apply)
+		}
+	}
+
+	s.snapshot = snapshot
+	for _, apply := range applies {
+		apply()
+	}
+
+	if len(restartRequired) > 0 {
+		slog.WarnContext(s.ctx, fmt.Sprintf("config reloaded, changes of %s require restart", strings.Join(restartRequired, ", ")))
+	} else {
+		slog.InfoContext(s.ctx, "config reloaded")
+	}
+	return restartRequired, nil
+}
+
+// diffOptions returns the mapstructure keys of fields which are different
+func diffOptions(old, new Options) []string {
+	var keys []string
+	oldValue, newValue := reflect.ValueOf(old), reflect.ValueOf(new)
+	for i := 0; i < oldValue.NumField(); i++ {
+		if !reflect.DeepEqual(oldValue.Field(i).Interface(), newValue.Field(i).Interface()) {
+			key, _, _ := strings.Cut(oldValue.Type().Field(i).Tag.Get("mapstructure"), ",")
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,57 @@
+package ginx
+
+import (
+	"github.com/ginx-contribs/ginx/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReload(t *testing.T) {
+	type limitConfig struct {
+		Limit  int           `mapstructure:"limit"`
+		Window time.Duration `mapstructure:"window"`
+	}
+
+	file := filepath.Join(t.TempDir(), "app.yaml")
+	write := func(content string) {
+		require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+	}
+	write("server:\n  address: :8080\nlimit:\n  limit: 10\n  window: 1s\n")
+
+	configOpts := []config.Option{config.WithFiles(file), config.WithKey("server")}
+	options, err := LoadOptions(configOpts...)
+	require.NoError(t, err)
+	server := New(WithOptions(options), WithConfig(configOpts...))
+
+	var updates []limitConfig
+	limit, err := Watch(server, "limit", func(cfg limitConfig) {
+		updates = append(updates, cfg)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, limitConfig{Limit: 10, Window: time.Second}, limit)
+
+	// nothing changed
+	restart, err := server.Reload()
+	require.NoError(t, err)
+	assert.Empty(t, restart)
+	assert.Empty(t, updates)
+
+	write("server:\n  address: :8081\nlimit:\n  limit: 20\n  window: 1s\n")
+	restart, err = server.Reload()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"address"}, restart)
+	assert.Equal(t, []limitConfig{{Limit: 20, Window: time.Second}}, updates)
+
+	// invalid config is not applied
+	write("server:\n  address: :8081\nlimit:\n  limit: 30\n  window: 1s\n  unknown: 1\n")
+	_, err = server.Reload()
+	assert.ErrorIs(t, err, config.ErrUnknownKeys)
+	assert.Len(t, updates, 1)
+
+	_, err = New().Reload()
+	assert.ErrorIs(t, err, ErrNoConfig)
+}
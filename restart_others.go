@@ -0,0 +1,21 @@
+//go:build !unix
+
+package ginx
+
+import (
+	"errors"
+)
+
+// inheritedListeners is not supported on this platform
+func inheritedListeners() ([]namedListener, error) {
+	return nil, nil
+}
+
+// restart is not supported on this platform
+func (s *Server) restart() error {
+	return errors.New("graceful restart is not supported on this platform")
+}
+
+func notifyReady() error {
+	return nil
+}
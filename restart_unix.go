@@ -0,0 +1,218 @@
+//go:build unix
+
+package ginx
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// environment variables of socket activation, see sd_listen_fds(3)
+const (
+	envListenFds     = "LISTEN_FDS"
+	envListenPid     = "LISTEN_PID"
+	envListenFdNames = "LISTEN_FDNAMES"
+	// fd of pipe used to notify parent process the new process is ready
+	envReadyFd = "GINX_READY_FD"
+
+	// the first passed fd, 0, 1, 2 are stdin, stdout and stderr
+	listenFdsStart = 3
+)
+
+// inheritedListeners returns the listeners passed by parent process or systemd by LISTEN_FDS,
+// the environment variables will be unset, so they will not be inherited by child processes.
+func inheritedListeners() ([]namedListener, error) {
+	fds := os.Getenv(envListenFds)
+	if fds == "" {
+		return nil, nil
+	}
+	pid := os.Getenv(envListenPid)
+	names := strings.Split(os.Getenv(envListenFdNames), ":")
+	defer func() {
+		_ = os.Unsetenv(envListenFds)
+		_ = os.Unsetenv(envListenPid)
+		_ = os.Unsetenv(envListenFdNames)
+	}()
+
+	// passed to other process
+	if pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return nil, nil
+	}
+
+	n, err := strconv.Atoi(fds)
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("invalid %s: %q", envListenFds, fds)
+	}
+
+	listeners := make([]namedListener, 0, n)
+	for i := 0; i < n; i++ {
+		fd := listenFdsStart + i
+		syscall.CloseOnExec(fd)
+
+		name := "fd://" + strconv.Itoa(fd)
+		if i < len(names) && names[i] != "" {
+			name, _ = url.QueryUnescape(names[i])
+		}
+
+		file := os.NewFile(uintptr(fd), name)
+		l, err := net.FileListener(file)
+		// FileListener duplicates the fd
+		_ = file.Close()
+		if err != nil {
+			for _, l := range listeners {
+				_ = l.Close()
+			}
+			return nil, fmt.Errorf("fd %d: %w", fd, err)
+		}
+		listeners = append(listeners, namedListener{Listener: l, name: name})
+	}
+	return listeners, nil
+}
+
+// restart starts a new process of the same executable with the same arguments, passes the bound listeners to it
+// by LISTEN_FDS, then waits for the new process to be ready. If it returns nil, the server should shut down.
+func (s *Server) restart() error {
+	files := make([]*os.File, 0, len(s.bound)+1)
+	defer func() {
+		for _, file := range files {
+			_ = file.Close()
+		}
+	}()
+
+	listeners := s.bound
+	if s.adminListener != nil {
+		// admin server of the new process takes it by name
+		listeners = append(listeners[:len(listeners):len(listeners)], namedListener{Listener: s.adminListener, name: adminListenerName})
+	}
+
+	names := make([]string, 0, len(listeners))
+	for _, l := range listeners {
+		fl, ok := l.Listener.(interface{ File() (*os.File, error) })
+		if !ok {
+			return fmt.Errorf("listener %s can not be passed to new process", l.name)
+		}
+		file, err := fl.File()
+		if err != nil {
+			return err
+		}
+		files = append(files, file)
+		names = append(names, url.QueryEscape(l.name))
+	}
+
+	readyR, readyW, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer readyR.Close()
+	files = append(files, readyW)
+
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	// os/exec calls *os.File.Fd which puts the file descriptions shared with the listeners into blocking mode,
+	// then accepting in this process may block forever, so pass the raw fds to syscall.ForkExec directly.
+	fds := []uintptr{os.Stdin.Fd(), os.Stdout.Fd(), os.Stderr.Fd()}
+	for _, file := range files {
+		fd, err := rawFd(file)
+		if err != nil {
+			return err
+		}
+		fds = append(fds, fd)
+	}
+
+	env := append(restartEnv(),
+		envListenFds+"="+strconv.Itoa(len(names)),
+		envListenFdNames+"="+strings.Join(names, ":"),
+		envReadyFd+"="+strconv.Itoa(listenFdsStart+len(names)),
+	)
+	pid, err := syscall.ForkExec(executable, os.Args, &syscall.ProcAttr{Env: env, Files: fds})
+	if err != nil {
+		return err
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	// close write end in this process, so that reading returns EOF if the new process exits before ready
+	_ = readyW.Close()
+
+	readyCh := make(chan error, 1)
+	go func() {
+		_, err := readyR.Read(make([]byte, 1))
+		readyCh <- err
+	}()
+
+	select {
+	case err = <-readyCh:
+	case <-time.After(s.restartTimeout):
+		err = errors.New("timeout")
+	}
+	if err != nil {
+		_ = process.Kill()
+		_, _ = process.Wait()
+		return fmt.Errorf("new process %d is not ready: %w", pid, err)
+	}
+
+	// socket files are used by the new process now, do not remove them when closing
+	for _, l := range s.bound {
+		if ul, ok := l.Listener.(*net.UnixListener); ok {
+			ul.SetUnlinkOnClose(false)
+		}
+	}
+	return process.Release()
+}
+
+// rawFd returns fd of file without changing its blocking mode
+func rawFd(file *os.File) (uintptr, error) {
+	rawConn, err := file.SyscallConn()
+	if err != nil {
+		return 0, err
+	}
+	var fd uintptr
+	err = rawConn.Control(func(f uintptr) {
+		fd = f
+	})
+	return fd, err
+}
+
+// restartEnv returns the environment variables of current process, excludes the ones used to pass listeners
+func restartEnv() []string {
+	env := os.Environ()
+	filtered := env[:0]
+	for _, kv := range env {
+		key, _, _ := strings.Cut(kv, "=")
+		switch key {
+		case envListenFds, envListenPid, envListenFdNames, envReadyFd:
+			continue
+		}
+		filtered = append(filtered, kv)
+	}
+	return filtered
+}
+
+// notifyReady notifies the parent process by writing the ready pipe, it does nothing if not started by restart.
+func notifyReady() error {
+	fd := os.Getenv(envReadyFd)
+	if fd == "" {
+		return nil
+	}
+	_ = os.Unsetenv(envReadyFd)
+
+	n, err := strconv.Atoi(fd)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %q", envReadyFd, fd)
+	}
+	pipe := os.NewFile(uintptr(n), "ready")
+	defer pipe.Close()
+	_, err = pipe.Write([]byte{1})
+	return err
+}
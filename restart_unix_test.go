@@ -0,0 +1,87 @@
+//go:build unix
+
+package ginx
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// envRestartChild marks the process is started by TestGracefulRestart
+const envRestartChild = "GINX_TEST_RESTART_CHILD"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(envRestartChild) != "" {
+		server := restartServer()
+		if err := server.Spin(); err != nil {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func restartServer() *Server {
+	server := New(WithAddress("127.0.0.1:0"), WithAdmin("127.0.0.1:0"), WithGracefulRestart(syscall.SIGUSR2, 10*time.Second))
+	server.RouterGroup().GET("/pid", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, strconv.Itoa(os.Getpid()))
+	})
+	return server
+}
+
+func TestGracefulRestart(t *testing.T) {
+	t.Setenv(envRestartChild, "1")
+
+	server := restartServer()
+	require.NoError(t, server.startAdmin())
+	require.NoError(t, server.freeze())
+	require.NoError(t, server.listen())
+	go server.serve()
+	url := "http://" + server.Addrs()[0].String() + "/pid"
+	adminURL := "http://" + server.AdminAddr().String() + "/livez"
+
+	// the new process can not be ready if it fails to listen on admin address
+	require.NoError(t, server.restart())
+	require.NoError(t, server.Shutdown(context.Background()))
+	server.stopAdmin()
+
+	// the admin listener is served by new process now
+	res, err := http.Get(adminURL)
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	// the listener is served by new process now
+	res, err = http.Get(url)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	pid, err := strconv.Atoi(string(body))
+	require.NoError(t, err)
+	assert.NotEqual(t, os.Getpid(), pid)
+
+	require.NoError(t, syscall.Kill(pid, syscall.SIGTERM))
+}
+
+func TestSameAddr(t *testing.T) {
+	server := New(WithAddress("127.0.0.1:0"))
+	require.NoError(t, server.listen())
+	defer server.bound[0].Close()
+	addr := server.Addrs()[0]
+	port := strconv.Itoa(addr.(*net.TCPAddr).Port)
+
+	assert.True(t, sameAddr(addr, ListenerOptions{Network: "tcp", Address: "127.0.0.1:" + port}))
+	assert.False(t, sameAddr(addr, ListenerOptions{Network: "tcp", Address: ":" + port}))
+	assert.False(t, sameAddr(addr, ListenerOptions{Network: "tcp", Address: "127.0.0.1:0"}))
+	assert.False(t, sameAddr(addr, ListenerOptions{Network: "unix", Address: "/tmp/ginx.sock"}))
+}
@@ -34,21 +34,27 @@ func (handler *RouterHandler) applyMeta(meta M) {
 	})
 }
 
+// getMeta returns the effective meta info which has inherited from its groups
 func (handler *RouterHandler) getMeta() routeMeta {
 	key := routeKey(handler.Method, handler.FullPath)
 	meta, e := handler.group.s.metadata.Get(key)
 	if !e {
 		return emptyRouteMeta
 	}
-	if meta.Group != nil {
-		meta.MetaData.applyM(meta.Group.getMeta().MetaData)
-	}
-	return meta
+	return handler.group.s.inheritMeta(meta)
 }
 
-// RouterGroup returns metadata route group
+// RouterGroup returns the root metadata route group of server
 func (s *Server) RouterGroup() *RouterGroup {
-	return &RouterGroup{current: &s.engine.RouterGroup, s: s}
+	if s.root == nil {
+		s.root = &RouterGroup{current: &s.engine.RouterGroup, s: s}
+	}
+	return s.root
+}
+
+// Walk walks all the routes registered by root router group
+func (s *Server) Walk(walkFn func(info RouteInfo)) {
+	s.RouterGroup().Walk(walkFn)
 }
 
 type RouterGroup struct {
@@ -75,17 +81,14 @@ func (group *RouterGroup) applyMeta(meta M) {
 	})
 }
 
-// getMeta returns meta info from *Server.metadata and its groups
+// getMeta returns the effective meta info which has inherited from its groups
 func (group *RouterGroup) getMeta() routeMeta {
 	routeKey := routeKey("group", group.current.BasePath())
 	meta, e := group.s.metadata.Get(routeKey)
 	if !e {
 		return emptyRouteMeta
 	}
-	if meta.Group != nil {
-		meta.MetaData.applyM(meta.Group.getMeta().MetaData)
-	}
-	return meta
+	return group.s.inheritMeta(meta)
 }
 
 func (group *RouterGroup) Group(path string, handlers ...gin.HandlerFunc) *RouterGroup {
@@ -123,6 +126,13 @@ func (group *RouterGroup) Handle(method string, path string, meta M, handlers ..
 	return handler
 }
 
+// HandleTyped registers the typed handler created by Typed after middlewares, its request and response types
+// are recorded into metadata with keys MetaRequest and MetaResponse unless they are present in meta.
+func (group *RouterGroup) HandleTyped(method string, path string, meta M, handler TypedHandler, middlewares ...gin.HandlerFunc) *RouterHandler {
+	chain := append(middlewares[:len(middlewares):len(middlewares)], handler.HandlerFunc)
+	return group.Handle(method, path, handler.meta(meta), chain...)
+}
+
 func (group *RouterGroup) Match(methods []string, path string, meta M, handlers ...gin.HandlerFunc) []*RouterHandler {
 	var hs []*RouterHandler
 	for _, method := range methods {
@@ -0,0 +1,125 @@
+package ginx
+
+import (
+	"context"
+	"reflect"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// State is the lifecycle state of server
+type State int32
+
+const (
+	StateIdle State = iota
+	// executing before starting hooks, or listening
+	StateStarting
+	// after started hooks are done
+	StateRunning
+	// shutting down, or executing on shutdown hooks
+	StateStopping
+	StateStopped
+)
+
+var stateNames = [...]string{"idle", "starting", "running", "stopping", "stopped"}
+
+func (s State) String() string {
+	if int(s) < len(stateNames) {
+		return stateNames[s]
+	}
+	return "unknown"
+}
+
+func (s State) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// State returns the current lifecycle state of server
+func (s *Server) State() State {
+	return State(s.state.Load())
+}
+
+func (s *Server) setState(state State) {
+	s.state.Store(int32(state))
+}
+
+// hook phases
+const (
+	PhaseBeforeStarting = "beforeStarting"
+	PhaseAfterStarted   = "afterStarted"
+	PhaseOnShutdown     = "onShutdown"
+)
+
+// HookStatus is the execution status of a hook
+type HookStatus struct {
+	Phase string `json:"phase"`
+	// function name of hook
+	Name string `json:"name"`
+	// pending, running, done or failed
+	Status    string        `json:"status"`
+	Error     string        `json:"error,omitempty"`
+	StartedAt time.Time     `json:"startedAt"`
+	Duration  time.Duration `json:"duration,omitempty"`
+}
+
+type hookRecorder struct {
+	mu       sync.Mutex
+	statuses []HookStatus
+}
+
+func (r *hookRecorder) add(phase string, hooks []HookFn) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	start := len(r.statuses)
+	for _, hook := range hooks {
+		r.statuses = append(r.statuses, HookStatus{Phase: phase, Name: funcName(hook), Status: "pending"})
+	}
+	return start
+}
+
+func (r *hookRecorder) update(i int, fn func(status *HookStatus)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fn(&r.statuses[i])
+}
+
+// HookStatus returns the execution status of hooks which have been executed or are going to be executed
+func (s *Server) HookStatus() []HookStatus {
+	s.hooks.mu.Lock()
+	defer s.hooks.mu.Unlock()
+	return append([]HookStatus(nil), s.hooks.statuses...)
+}
+
+func (s *Server) executeHooks(ctx context.Context, phase string, hooks ...HookFn) error {
+	start := s.hooks.add(phase, hooks)
+	for i, hook := range hooks {
+		begin := time.Now()
+		s.hooks.update(start+i, func(status *HookStatus) {
+			status.Status = "running"
+			status.StartedAt = begin
+		})
+		err := hook(ctx)
+		s.metrics.observeHook(phase, funcName(hook), err, time.Since(begin))
+		s.hooks.update(start+i, func(status *HookStatus) {
+			status.Duration = time.Since(begin)
+			if err != nil {
+				status.Status = "failed"
+				status.Error = err.Error()
+			} else {
+				status.Status = "done"
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func funcName(fn any) string {
+	if f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()); f != nil {
+		return f.Name()
+	}
+	return "unknown"
+}
@@ -0,0 +1,403 @@
+package ginx
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CertExpiryFn will be called when a certificate is going to expire, remaining is negative if it has expired.
+type CertExpiryFn = func(ctx context.Context, cert *x509.Certificate, remaining time.Duration)
+
+// CertStore holds certificates loaded from files, and swaps them atomically when files are modified.
+// It selects certificate by SNI through GetCertificate, the first pair is used as default.
+type CertStore struct {
+	pairs []CertOptions
+
+	mu       sync.Mutex
+	modTimes map[string]time.Time
+	certs    atomic.Pointer[certSet]
+}
+
+type certSet struct {
+	def    *tls.Certificate
+	byName map[string]*tls.Certificate
+	all    []*tls.Certificate
+}
+
+// NewCertStore loads certificates from the key pair files
+func NewCertStore(pairs ...CertOptions) (*CertStore, error) {
+	if len(pairs) == 0 {
+		return nil, errors.New("no certificate configured")
+	}
+	store := &CertStore{pairs: pairs}
+	if err := store.Reload(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// Reload loads all the certificates, the old ones are kept if any of them fails.
+func (c *CertStore) Reload() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	modTimes := make(map[string]time.Time, len(c.pairs)*2)
+	set := &certSet{byName: make(map[string]*tls.Certificate)}
+	for _, pair := range c.pairs {
+		for _, file := range []string{pair.Cert, pair.Key} {
+			info, err := os.Stat(file)
+			if err != nil {
+				return err
+			}
+			modTimes[file] = info.ModTime()
+		}
+
+		cert, err := tls.LoadX509KeyPair(pair.Cert, pair.Key)
+		if err != nil {
+			return fmt.Errorf("load certificate %s: %w", pair.Cert, err)
+		}
+		if cert.Leaf == nil {
+			if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+				return fmt.Errorf("parse certificate %s: %w", pair.Cert, err)
+			}
+		}
+		set.add(&cert)
+	}
+
+	c.modTimes = modTimes
+	c.certs.Store(set)
+	return nil
+}
+
+// Modified reports whether any of certificate files has been modified since last loading
+func (c *CertStore) Modified() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for file, modTime := range c.modTimes {
+		info, err := os.Stat(file)
+		if err != nil || !info.ModTime().Equal(modTime) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetCertificate selects certificate by server name, it could be used as tls.Config.GetCertificate
+func (c *CertStore) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	set := c.certs.Load()
+	name := strings.ToLower(strings.TrimSuffix(hello.ServerName, "."))
+	if name == "" {
+		return set.def, nil
+	}
+	if cert, ok := set.byName[name]; ok {
+		return cert, nil
+	}
+	// wildcard matches only one label
+	if _, parent, found := strings.Cut(name, "."); found {
+		if cert, ok := set.byName["*."+parent]; ok {
+			return cert, nil
+		}
+	}
+	return set.def, nil
+}
+
+// Leaves returns the parsed leaf certificates currently in use
+func (c *CertStore) Leaves() []*x509.Certificate {
+	set := c.certs.Load()
+	leaves := make([]*x509.Certificate, 0, len(set.all))
+	for _, cert := range set.all {
+		leaves = append(leaves, cert.Leaf)
+	}
+	return leaves
+}
+
+// Watch checks the files every interval, and reloads certificates if they are modified, it blocks until ctx done.
+func (c *CertStore) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !c.Modified() {
+				continue
+			}
+			if err := c.Reload(); err != nil {
+				slog.ErrorContext(ctx, "failed to reload tls certificates", slog.Any("error", err))
+			} else {
+				slog.InfoContext(ctx, "tls certificates reloaded")
+			}
+		}
+	}
+}
+
+// CheckExpiry calls fn for each certificate which expires within the given duration
+func (c *CertStore) CheckExpiry(ctx context.Context, within time.Duration, fn CertExpiryFn) {
+	now := time.Now()
+	for _, leaf := range c.Leaves() {
+		if remaining := leaf.NotAfter.Sub(now); remaining <= within {
+			fn(ctx, leaf, remaining)
+		}
+	}
+}
+
+func (s *certSet) add(cert *tls.Certificate) {
+	if s.def == nil {
+		s.def = cert
+	}
+	s.all = append(s.all, cert)
+
+	names := cert.Leaf.DNSNames
+	if len(names) == 0 && cert.Leaf.Subject.CommonName != "" {
+		names = []string{cert.Leaf.Subject.CommonName}
+	}
+	for _, name := range names {
+		name = strings.ToLower(name)
+		// the first one wins
+		if _, exists := s.byName[name]; !exists {
+			s.byName[name] = cert
+		}
+	}
+}
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+func parseTLSVersion(version string) (uint16, error) {
+	if version == "" {
+		return 0, nil
+	}
+	v, ok := tlsVersions[strings.TrimPrefix(strings.ToUpper(version), "TLS")]
+	if !ok {
+		return 0, fmt.Errorf("unknown tls version %q", version)
+	}
+	return v, nil
+}
+
+func parseCipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	suites := append(tls.CipherSuites(), tls.InsecureCipherSuites()...)
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		found := false
+		for _, suite := range suites {
+			if strings.EqualFold(suite.Name, name) {
+				ids = append(ids, suite.ID)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown cipher suite %q", name)
+		}
+	}
+	return ids, nil
+}
+
+func parseCurves(names []string) ([]tls.CurveID, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	curves := []tls.CurveID{tls.X25519, tls.CurveP256, tls.CurveP384, tls.CurveP521}
+	ids := make([]tls.CurveID, 0, len(names))
+	for _, name := range names {
+		found := false
+		for _, curve := range curves {
+			// both CurveP256 and P256 are accepted
+			if strings.EqualFold(curve.String(), name) || strings.EqualFold(strings.TrimPrefix(curve.String(), "Curve"), name) {
+				ids = append(ids, curve)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown curve %q", name)
+		}
+	}
+	return ids, nil
+}
+
+var clientAuthTypes = map[string]tls.ClientAuthType{
+	"none":               tls.NoClientCert,
+	"request":            tls.RequestClientCert,
+	"require":            tls.RequireAnyClientCert,
+	"verify-if-given":    tls.VerifyClientCertIfGiven,
+	"require-and-verify": tls.RequireAndVerifyClientCert,
+}
+
+func parseClientAuth(mode string, hasCAs bool) (tls.ClientAuthType, error) {
+	if mode == "" {
+		if hasCAs {
+			return tls.RequireAndVerifyClientCert, nil
+		}
+		return tls.NoClientCert, nil
+	}
+	auth, ok := clientAuthTypes[strings.ToLower(mode)]
+	if !ok {
+		return 0, fmt.Errorf("unknown client auth mode %q", mode)
+	}
+	if auth >= tls.VerifyClientCertIfGiven && !hasCAs {
+		return 0, fmt.Errorf("client auth mode %s requires client CAs", mode)
+	}
+	return auth, nil
+}
+
+// loadCertPool loads PEM encoded certificates from files into pool
+func loadCertPool(files []string) (*x509.CertPool, error) {
+	if len(files) == 0 {
+		return nil, nil
+	}
+	pool := x509.NewCertPool()
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificate found in %s", file)
+		}
+	}
+	return pool, nil
+}
+
+// buildTLSConfig builds tls.Config from options based on the given config, certificates are provided by the store
+func buildTLSConfig(base *tls.Config, options *TLSOptions, store *CertStore) (*tls.Config, error) {
+	minVersion, err := parseTLSVersion(options.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+	maxVersion, err := parseTLSVersion(options.MaxVersion)
+	if err != nil {
+		return nil, err
+	}
+	cipherSuites, err := parseCipherSuites(options.CipherSuites)
+	if err != nil {
+		return nil, err
+	}
+	curves, err := parseCurves(options.CurvePreferences)
+	if err != nil {
+		return nil, err
+	}
+	clientCAs, err := loadCertPool(options.ClientCAs)
+	if err != nil {
+		return nil, err
+	}
+	clientAuth, err := parseClientAuth(options.ClientAuth, clientCAs != nil)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if base != nil {
+		config = base.Clone()
+	}
+	if minVersion != 0 {
+		config.MinVersion = minVersion
+	}
+	if maxVersion != 0 {
+		config.MaxVersion = maxVersion
+	}
+	if len(cipherSuites) > 0 {
+		config.CipherSuites = cipherSuites
+	}
+	if len(curves) > 0 {
+		config.CurvePreferences = curves
+	}
+	if len(options.NextProtos) > 0 {
+		config.NextProtos = options.NextProtos
+	}
+	if clientCAs != nil {
+		config.ClientCAs = clientCAs
+	}
+	if clientAuth != tls.NoClientCert {
+		config.ClientAuth = clientAuth
+	}
+	config.GetCertificate = store.GetCertificate
+	return config, nil
+}
+
+// setupTLS loads certificates and builds tls config for http server, then starts watching certificate files.
+func (s *Server) setupTLS() error {
+	options := s.options.TLS
+	if options == nil || s.certs != nil {
+		return nil
+	}
+
+	var pairs []CertOptions
+	if options.Cert != "" || options.Key != "" {
+		pairs = append(pairs, CertOptions{Cert: options.Cert, Key: options.Key})
+	}
+	pairs = append(pairs, options.Certificates...)
+
+	store, err := NewCertStore(pairs...)
+	if err != nil {
+		return err
+	}
+	config, err := buildTLSConfig(s.httpserver.TLSConfig, options, store)
+	if err != nil {
+		return err
+	}
+	s.certs = store
+	s.httpserver.TLSConfig = config
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	s.stopTLS = cancel
+
+	if options.ReloadInterval >= 0 {
+		interval := options.ReloadInterval
+		if interval == 0 {
+			interval = time.Minute
+		}
+		go store.Watch(ctx, interval)
+	}
+
+	if s.certExpiry != nil {
+		go func() {
+			ticker := time.NewTicker(certExpiryCheckInterval)
+			defer ticker.Stop()
+			for {
+				store.CheckExpiry(ctx, options.ExpiryWarning, s.certExpiry)
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}()
+	}
+	return nil
+}
+
+// how often to check certificate expiry
+const certExpiryCheckInterval = time.Hour * 12
+
+// TLSConfig returns the tls config used by http server, it is available after server starting,
+// returns nil if TLS is not configured.
+func (s *Server) TLSConfig() *tls.Config {
+	if s.certs == nil {
+		return nil
+	}
+	return s.httpserver.TLSConfig
+}
+
+// CertStore returns the certificates source used by http server, it is available after server starting,
+// returns nil if TLS is not configured.
+func (s *Server) CertStore() *CertStore {
+	return s.certs
+}
@@ -0,0 +1,157 @@
+package ginx
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// writeCert generates a self-signed certificate for names, and writes it into dir
+func writeCert(t *testing.T, dir, name string, notAfter time.Time, dnsNames ...string) CertOptions {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     dnsNames,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	pair := CertOptions{Cert: filepath.Join(dir, name+".crt"), Key: filepath.Join(dir, name+".key")}
+	require.NoError(t, os.WriteFile(pair.Cert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(pair.Key, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600))
+	return pair
+}
+
+func TestCertStore(t *testing.T) {
+	dir := t.TempDir()
+	expire := time.Now().Add(time.Hour * 24 * 365)
+	def := writeCert(t, dir, "default", expire, "localhost")
+	wildcard := writeCert(t, dir, "wildcard", time.Now().Add(time.Hour), "*.example.com")
+
+	store, err := NewCertStore(def, wildcard)
+	require.NoError(t, err)
+
+	subject := func(serverName string) string {
+		cert, err := store.GetCertificate(&tls.ClientHelloInfo{ServerName: serverName})
+		require.NoError(t, err)
+		return cert.Leaf.Subject.CommonName
+	}
+	assert.Equal(t, "default", subject(""))
+	assert.Equal(t, "default", subject("LOCALHOST"))
+	assert.Equal(t, "wildcard", subject("api.example.com"))
+	assert.Equal(t, "default", subject("a.b.example.com"))
+
+	var expiring []string
+	store.CheckExpiry(context.Background(), time.Hour*24, func(ctx context.Context, cert *x509.Certificate, remaining time.Duration) {
+		expiring = append(expiring, cert.Subject.CommonName)
+	})
+	assert.Equal(t, []string{"wildcard"}, expiring)
+
+	// rotate
+	assert.False(t, store.Modified())
+	writeCert(t, dir, "default", expire, "localhost", "rotated.local")
+	require.NoError(t, os.Chtimes(def.Cert, time.Now(), time.Now().Add(time.Second)))
+	assert.True(t, store.Modified())
+	require.NoError(t, store.Reload())
+	assert.Equal(t, "default", subject("rotated.local"))
+
+	// keep the old ones if reloading fails
+	require.NoError(t, os.WriteFile(wildcard.Cert, []byte("broken"), 0o600))
+	assert.Error(t, store.Reload())
+	assert.Equal(t, "wildcard", subject("api.example.com"))
+}
+
+func TestTLSServer(t *testing.T) {
+	dir := t.TempDir()
+	pair := writeCert(t, dir, "server", time.Now().Add(time.Hour), "localhost")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		expiring atomic.Int32
+		version  uint16
+	)
+	server := New(
+		WithCtx(ctx),
+		WithAddress("127.0.0.1:0"),
+		WithTLSOptions(TLSOptions{
+			Cert:             pair.Cert,
+			Key:              pair.Key,
+			MinVersion:       "1.3",
+			CurvePreferences: []string{"X25519", "P256"},
+		}),
+		WithCertExpiry(func(ctx context.Context, cert *x509.Certificate, remaining time.Duration) {
+			expiring.Add(1)
+		}),
+		WithAfterStarted(func(ctx context.Context) error {
+			defer cancel()
+			client := http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+			res, err := client.Get("https://" + AddrsFromCtx(ctx)[0].String() + "/ping")
+			if err != nil {
+				return err
+			}
+			res.Body.Close()
+			version = res.TLS.Version
+			return nil
+		}),
+	)
+	server.RouterGroup().GET("/ping", func(ctx *gin.Context) {})
+
+	require.NoError(t, server.Spin())
+	assert.Equal(t, uint16(tls.VersionTLS13), version)
+	assert.Equal(t, uint16(tls.VersionTLS13), server.TLSConfig().MinVersion)
+	assert.Eventually(t, func() bool { return expiring.Load() == 1 }, time.Second, time.Millisecond*10)
+}
+
+func TestParseTLSOptions(t *testing.T) {
+	_, err := parseTLSVersion("1.4")
+	assert.Error(t, err)
+	v, err := parseTLSVersion("TLS1.2")
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS12), v)
+
+	suites, err := parseCipherSuites([]string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"})
+	assert.NoError(t, err)
+	assert.Equal(t, []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}, suites)
+	_, err = parseCipherSuites([]string{"unknown"})
+	assert.Error(t, err)
+
+	curves, err := parseCurves([]string{"p384", "CurveP521"})
+	assert.NoError(t, err)
+	assert.Equal(t, []tls.CurveID{tls.CurveP384, tls.CurveP521}, curves)
+
+	auth, err := parseClientAuth("", true)
+	assert.NoError(t, err)
+	assert.Equal(t, tls.RequireAndVerifyClientCert, auth)
+	auth, err = parseClientAuth("request", false)
+	assert.NoError(t, err)
+	assert.Equal(t, tls.RequestClientCert, auth)
+	_, err = parseClientAuth("verify-if-given", false)
+	assert.Error(t, err)
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/ginx-contribs/ginx/pkg/resp"
+	"github.com/ginx-contribs/ginx/pkg/resp/statuserr"
 	localeen "github.com/go-playground/locales/en"
 	unitrans "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
@@ -89,12 +90,17 @@ type ValidateTranslator func(ctx *gin.Context, val any, err error, translator un
 func defaultValidateErrTranslator(ctx *gin.Context, val any, err error, translator unitrans.Translator) {
 	var validationErrors validator.ValidationErrors
 	if errors.As(err, &validationErrors) {
-		var errorMsg []string
+		var (
+			errorMsg []string
+			fields   []statuserr.FieldError
+		)
 		for _, validateErr := range validationErrors {
-			errorMsg = append(errorMsg, validateErr.Translate(translator))
+			msg := validateErr.Translate(translator)
+			errorMsg = append(errorMsg, msg)
+			fields = append(fields, statuserr.FieldError{Field: validateErr.Field(), Detail: msg})
 		}
-		// this error will be shown in access log
-		resp.Fail(ctx).Error(errors.New(strings.Join(errorMsg, ","))).JSON()
+		// this error will be shown in access log, fields are rendered by the envelope supports, like resp.ProblemEnvelope
+		resp.Fail(ctx).Error(statuserr.BadRequest(errors.New(strings.Join(errorMsg, ","))).SetFields(fields...)).JSON()
 		return
 	}
 	resp.Fail(ctx).Error(errors.New("params validate failed")).JSON()
@@ -198,3 +204,22 @@ func ShouldValidateTOML(ctx *gin.Context, val any) error {
 	}
 	return nil
 }
+
+// ShouldBindPage binds page request from query, and normalizes it with resp.DefaultPageOptions
+func ShouldBindPage(ctx *gin.Context) (resp.PageRequest, error) {
+	return ShouldBindPageWith(ctx, resp.DefaultPageOptions)
+}
+
+// ShouldBindPageWith binds page request from query, and normalizes it with options, like max page size.
+func ShouldBindPageWith(ctx *gin.Context, options resp.PageOptions) (resp.PageRequest, error) {
+	var page resp.PageRequest
+	if err := ShouldValidateQuery(ctx, &page); err != nil {
+		return page, err
+	}
+	if err := page.Normalize(options); err != nil {
+		// it is not a validation error, respond the reason directly
+		resp.Fail(ctx).Error(statuserr.BadRequest(err)).JSON()
+		return page, err
+	}
+	return page, nil
+}